@@ -6,11 +6,27 @@ import (
 	"strings"
 
 	"craft/internal/state"
+	"craft/internal/undo"
 	"craft/internal/workflow"
 )
 
+var acceptCommand = &Command{
+	Name:    "accept",
+	Args:    "[note]",
+	Summary: "Confirm alignment and advance to shaping",
+	Flags: []Flag{
+		{Name: "skip-shaping", Usage: "Skip shaping phase, advance directly to building"},
+		{Name: "appetite", Value: "d", Usage: "Time allowed for building, e.g. 2w, 3d, 1w2d"},
+	},
+	Run: runAccept,
+}
+
 // Accept confirms alignment and advances from thinking to shaping (or building with --skip-shaping).
 func Accept(args []string) int {
+	return acceptCommand.Execute(args)
+}
+
+func runAccept(p *Parsed) int {
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
@@ -22,21 +38,24 @@ func Accept(args []string) int {
 		return 1
 	}
 
-	// Check for --skip-shaping flag
-	skipShaping := false
-	var filteredArgs []string
-	for _, arg := range args {
-		if arg == "--skip-shaping" {
-			skipShaping = true
-		} else {
-			filteredArgs = append(filteredArgs, arg)
-		}
+	if blockedByConcerns(w, workflow.ConcernReject) {
+		return 1
+	}
+
+	appetite, appetiteSet, err := appetiteFlag(p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if appetiteSet {
+		w.Appetite = appetite
 	}
+	skipShaping := p.Bool("skip-shaping")
 
 	// Get optional note for history
 	var note string
-	if len(filteredArgs) > 0 {
-		note = strings.Join(filteredArgs, " ")
+	if len(p.Args) > 0 {
+		note = strings.Join(p.Args, " ")
 		note = strings.Trim(note, "\"'")
 		note = strings.TrimSpace(note)
 		w.AddNote(note)
@@ -48,20 +67,31 @@ func Accept(args []string) int {
 		targetState = state.Building
 	}
 
+	// Later changes to the intent can be detected against this
+	w.FrozenIntent = w.HashIntent()
+
 	if err := w.TransitionWithNote(targetState, note); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
+	if err := undo.Save("accept"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
 	if err := w.Save(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
 	if skipShaping {
-		fmt.Println("Intent frozen. State: building")
+		fmt.Printf("Intent frozen (%s). State: building\n", w.FrozenIntent)
+		if w.Appetite > 0 {
+			fmt.Printf("Appetite: %s\n", workflow.FormatDuration(w.Appetite))
+		}
 	} else {
-		fmt.Println("Intent frozen. State: shaping")
+		fmt.Printf("Intent frozen (%s). State: shaping\n", w.FrozenIntent)
 		fmt.Println()
 		fmt.Println("Structure your work, then run `craft approve` to start building.")
 		fmt.Println("Or run `craft shape --generate` for AI assistance.")
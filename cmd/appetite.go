@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"craft/internal/state"
+	"craft/internal/undo"
+	"craft/internal/workflow"
+)
+
+var appetiteCommand = &Command{
+	Name:    "appetite",
+	Args:    "[d|none]",
+	Summary: "Show building time against the appetite, or set it",
+	Run:     runAppetite,
+}
+
+var checkCommand = &Command{
+	Name:    "check",
+	Summary: "Exit non-zero if building has exceeded the appetite",
+	Run:     runCheck,
+}
+
+// Appetite shows how building time compares to the appetite, or sets it.
+func Appetite(args []string) int {
+	return appetiteCommand.Execute(args)
+}
+
+func runAppetite(p *Parsed) int {
+	args := p.Args
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	if len(args) == 0 {
+		fmt.Printf("Appetite: %s\n", formatAppetite(w, time.Now()))
+		return 0
+	}
+
+	appetite, err := parseAppetite(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Usage: craft appetite [<duration> | none]")
+		return 1
+	}
+
+	// Extending or cutting the appetite is a decision worth keeping
+	if appetite != w.Appetite {
+		w.RecordTransition(appetiteChange(w.Appetite, appetite))
+		w.Appetite = appetite
+
+		if err := undo.Save("appetite"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if err := w.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if appetite == 0 {
+		fmt.Println("Appetite removed.")
+	} else {
+		fmt.Printf("Appetite set: %s\n", workflow.FormatDuration(appetite))
+	}
+	return 0
+}
+
+// appetiteChange describes a new appetite for the history.
+func appetiteChange(from, to time.Duration) string {
+	switch {
+	case to == 0:
+		return "Appetite removed (was " + workflow.FormatDuration(from) + ")"
+	case from == 0:
+		return "Appetite set to " + workflow.FormatDuration(to)
+	case to > from:
+		return fmt.Sprintf("Appetite extended to %s (was %s)", workflow.FormatDuration(to), workflow.FormatDuration(from))
+	default:
+		return fmt.Sprintf("Appetite cut to %s (was %s)", workflow.FormatDuration(to), workflow.FormatDuration(from))
+	}
+}
+
+// Check exits non-zero if building has run past the appetite.
+func Check(args []string) int {
+	return checkCommand.Execute(args)
+}
+
+func runCheck(_ *Parsed) int {
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	now := time.Now()
+	if w.AppetiteExceeded(now) {
+		fmt.Fprintln(os.Stderr, appetiteWarning(w, now))
+		return 1
+	}
+
+	fmt.Printf("Appetite: %s\n", formatAppetite(w, now))
+	return 0
+}
+
+// parseAppetite parses an appetite flag value. "none" clears the appetite.
+func parseAppetite(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "none" || s == "off" {
+		return 0, nil
+	}
+	return workflow.ParseAppetite(s)
+}
+
+// appetiteFlag returns the parsed --appetite value, if it was given.
+func appetiteFlag(p *Parsed) (appetite time.Duration, set bool, err error) {
+	if !p.Bool("appetite") {
+		return 0, false, nil
+	}
+	appetite, err = parseAppetite(p.String("appetite"))
+	if err != nil {
+		return 0, false, err
+	}
+	return appetite, true, nil
+}
+
+// formatAppetite renders building time against the appetite on one line.
+func formatAppetite(w *workflow.Workflow, now time.Time) string {
+	if w.Appetite == 0 {
+		return "(none)"
+	}
+
+	appetite := workflow.FormatDuration(w.Appetite)
+	building := w.PhaseDuration(state.Building, now)
+	switch {
+	case building == 0:
+		return appetite + " (building not started)"
+	case building > w.Appetite:
+		return fmt.Sprintf("%s, building for %s (%s over)", appetite, roundDuration(building), roundDuration(building-w.Appetite))
+	default:
+		return fmt.Sprintf("%s, building for %s (%s left)", appetite, roundDuration(building), roundDuration(w.Appetite-building))
+	}
+}
+
+// appetiteWarning explains an exceeded appetite and what to do about it.
+func appetiteWarning(w *workflow.Workflow, now time.Time) string {
+	over := w.PhaseDuration(state.Building, now) - w.Appetite
+	return fmt.Sprintf("Warning: Building has exceeded its %s appetite by %s. Cut scope, or extend with `craft appetite <duration>`.",
+		workflow.FormatDuration(w.Appetite), roundDuration(over))
+}
+
+// roundDuration drops precision that doesn't matter at the given scale.
+func roundDuration(d time.Duration) string {
+	if d >= 24*time.Hour {
+		d = d.Round(time.Hour)
+	} else {
+		d = d.Round(time.Minute)
+	}
+	return workflow.FormatDuration(d)
+}
+
+// formatPhases renders per-phase durations, e.g. "thinking 2h, building 3d".
+func formatPhases(phases []workflow.Phase) string {
+	parts := make([]string, len(phases))
+	for i, p := range phases {
+		parts[i] = fmt.Sprintf("%s %s", p.State, roundDuration(p.Duration))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -6,11 +6,25 @@ import (
 
 	"craft/internal/state"
 	"craft/internal/structure"
+	"craft/internal/undo"
 	"craft/internal/workflow"
 )
 
+var approveCommand = &Command{
+	Name:    "approve",
+	Summary: "Approve structure and advance to building",
+	Flags: []Flag{
+		{Name: "appetite", Value: "d", Usage: "Set or change the appetite as building starts"},
+	},
+	Run: runApprove,
+}
+
 // Approve approves the structure and advances from shaping to building.
 func Approve(args []string) int {
+	return approveCommand.Execute(args)
+}
+
+func runApprove(p *Parsed) int {
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
@@ -25,13 +39,36 @@ func Approve(args []string) int {
 		return 1
 	}
 
+	if blockedByConcerns(w, workflow.ConcernRevise) {
+		return 1
+	}
+
 	// Check that pitch.md exists
 	if !structure.HasPitch() {
 		fmt.Fprintln(os.Stderr, "Error: No structure found. Create .craft/pitch.md or run `craft shape --generate`.")
 		return 1
 	}
 
-	if err := w.TransitionWithNote(state.Building, ""); err != nil {
+	note, ok := twoPersonNote(w)
+	if !ok {
+		return 1
+	}
+
+	appetite, appetiteSet, err := appetiteFlag(p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if appetiteSet {
+		w.Appetite = appetite
+	}
+
+	if err := w.TransitionWithNote(state.Building, note); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := undo.Save("approve"); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
@@ -42,5 +79,8 @@ func Approve(args []string) int {
 	}
 
 	fmt.Println("Structure approved. State: building")
+	if w.Appetite > 0 {
+		fmt.Printf("Appetite: %s\n", workflow.FormatDuration(w.Appetite))
+	}
 	return 0
 }
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"craft/internal/undo"
+	"craft/internal/usage"
+	"craft/internal/workflow"
+)
+
+var budgetCommand = &Command{
+	Name:    "budget",
+	Args:    "[usd|none]",
+	Summary: "Show AI spend, or set the workflow's AI budget",
+	Run:     runBudget,
+}
+
+// Budget shows AI spend for the workflow, or sets its budget in USD.
+func Budget(args []string) int {
+	return budgetCommand.Execute(args)
+}
+
+func runBudget(p *Parsed) int {
+	args := p.Args
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	if len(args) == 0 {
+		prices, err := usage.Prices()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Usage: %s\n", formatUsage(usage.Summarize(w.Usage, prices), w.Budget))
+		return 0
+	}
+
+	amount, err := parseBudget(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Usage: craft budget [<usd> | none]")
+		return 1
+	}
+
+	if amount != w.Budget {
+		w.RecordTransition(budgetChange(w.Budget, amount))
+		w.Budget = amount
+
+		if err := undo.Save("budget"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if err := w.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if amount == 0 {
+		fmt.Println("Budget removed.")
+	} else {
+		fmt.Printf("Budget set: $%.2f\n", amount)
+	}
+	return 0
+}
+
+// budgetChange describes a new budget for the history.
+func budgetChange(from, to float64) string {
+	switch {
+	case to == 0:
+		return fmt.Sprintf("Budget removed (was $%.2f)", from)
+	case from == 0:
+		return fmt.Sprintf("Budget set to $%.2f", to)
+	case to > from:
+		return fmt.Sprintf("Budget raised to $%.2f (was $%.2f)", to, from)
+	default:
+		return fmt.Sprintf("Budget lowered to $%.2f (was $%.2f)", to, from)
+	}
+}
+
+// parseBudget parses a USD amount such as "5", "2.50" or "$3". "none" clears the budget.
+func parseBudget(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "none" || s == "off" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(strings.TrimPrefix(s, "$"), 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid budget %q", s)
+	}
+	return amount, nil
+}
+
+// checkBudget returns an error if the workflow's AI budget is spent,
+// counting pending calls not yet recorded in the workflow.
+func checkBudget(w *workflow.Workflow, pending ...usage.Call) error {
+	if w.Budget <= 0 {
+		return nil
+	}
+	prices, err := usage.Prices()
+	if err != nil {
+		return err
+	}
+	spent := usage.Summarize(append(slices.Clip(w.Usage), pending...), prices).Cost
+	if spent >= w.Budget {
+		return fmt.Errorf("AI budget exceeded ($%.4f of $%.2f). Run `craft budget <usd>` to raise it", spent, w.Budget)
+	}
+	return nil
+}
+
+// warnUnpriced warns when calls with no known price are left out of the
+// budget, so a budget isn't trusted while it can't trip.
+func warnUnpriced(w *workflow.Workflow) {
+	if w.Budget <= 0 {
+		return
+	}
+	prices, err := usage.Prices()
+	if err != nil {
+		return
+	}
+	if n := usage.Summarize(w.Usage, prices).Unpriced; n > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d AI call(s) have no known price and don't count toward the budget. Set CRAFT_AI_PRICES to price them.\n", n)
+	}
+}
+
+// recordUsage saves AI calls to the workflow's usage ledger.
+func recordUsage(w *workflow.Workflow, calls []usage.Call) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	w.RecordUsage(calls...)
+	return w.Save()
+}
+
+// formatUsage renders a usage summary on one line.
+func formatUsage(s usage.Summary, budget float64) string {
+	if s.Calls == 0 {
+		line := "(no AI calls)"
+		if budget > 0 {
+			line += fmt.Sprintf(", budget $%.2f", budget)
+		}
+		return line
+	}
+
+	calls := "calls"
+	if s.Calls == 1 {
+		calls = "call"
+	}
+	line := fmt.Sprintf("%d %s, %d tokens (%d prompt, %d completion), ~$%.4f",
+		s.Calls, calls, s.Tokens(), s.PromptTokens, s.CompletionTokens, s.Cost)
+	if s.Unpriced > 0 {
+		line += fmt.Sprintf(" (%d unpriced)", s.Unpriced)
+	}
+	if budget > 0 {
+		line += fmt.Sprintf(" of $%.2f budget", budget)
+	}
+	return line
+}
@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"craft/internal/usage"
 	"craft/internal/workflow"
 )
 
@@ -14,6 +20,10 @@ func setupTest(t *testing.T) (cleanup func()) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
 	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
 	return func() {
 		os.Chdir(origDir)
 	}
@@ -154,7 +164,7 @@ func TestAcceptSkipShapingWithNote(t *testing.T) {
 	if w.State != "building" {
 		t.Errorf("State = %s, want building", w.State)
 	}
-	if len(w.Notes) != 1 || w.Notes[0] != "Quick fix" {
+	if len(w.Notes) != 1 || w.Notes[0].Text != "Quick fix" {
 		t.Errorf("Notes = %v, want [Quick fix]", w.Notes)
 	}
 }
@@ -167,7 +177,7 @@ func TestAcceptWithNote(t *testing.T) {
 	Accept([]string{"Decided to use token bucket"})
 
 	w, _ := workflow.Load()
-	if len(w.Notes) != 1 || w.Notes[0] != "Decided to use token bucket" {
+	if len(w.Notes) != 1 || w.Notes[0].Text != "Decided to use token bucket" {
 		t.Errorf("Notes = %v, want [Decided to use token bucket]", w.Notes)
 	}
 }
@@ -330,6 +340,9 @@ func TestFullWorkflowWithSkipShaping(t *testing.T) {
 	if code := Reject([]string{"Need to consider rate limit headers"}); code != 0 {
 		t.Fatalf("Reject() = %d", code)
 	}
+	if code := Resolve([]string{"C1", "Send X-RateLimit-Remaining"}); code != 0 {
+		t.Fatalf("Resolve() = %d", code)
+	}
 
 	// Think and accept (skip shaping)
 	if code := Think(nil); code != 0 {
@@ -653,8 +666,8 @@ func TestReviseFromShaping(t *testing.T) {
 	if len(w.Notes) != 1 {
 		t.Errorf("Notes count = %d, want 1", len(w.Notes))
 	}
-	if !strings.Contains(w.Notes[0], "[revise]") {
-		t.Errorf("Note should contain [revise] prefix, got %q", w.Notes[0])
+	if !strings.Contains(w.Notes[0].Text, "[revise]") {
+		t.Errorf("Note should contain [revise] prefix, got %q", w.Notes[0].Text)
 	}
 }
 
@@ -724,3 +737,1284 @@ func TestFullWorkflowWithShaping(t *testing.T) {
 		t.Errorf("Final state = %s, want shipped", w.State)
 	}
 }
+
+func TestPromptsList(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	code := Prompts(nil)
+	if code != 0 {
+		t.Errorf("Prompts() = %d, want 0", code)
+	}
+}
+
+func TestPromptsShow(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	if code := Prompts([]string{"show", "review"}); code != 0 {
+		t.Errorf("Prompts(show review) = %d, want 0", code)
+	}
+	if code := Prompts([]string{"show", "unknown"}); code != 1 {
+		t.Errorf("Prompts(show unknown) = %d, want 1", code)
+	}
+	if code := Prompts([]string{"show"}); code != 1 {
+		t.Errorf("Prompts(show) = %d, want 1", code)
+	}
+}
+
+func TestPromptsEject(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	code := Prompts([]string{"eject", "cards"})
+	if code != 0 {
+		t.Errorf("Prompts(eject cards) = %d, want 0", code)
+	}
+	if _, err := os.Stat(".craft/prompts/cards.tmpl"); err != nil {
+		t.Error(".craft/prompts/cards.tmpl should exist after eject")
+	}
+	if _, err := os.Stat(".craft/prompts/review.tmpl"); err == nil {
+		t.Error(".craft/prompts/review.tmpl should not exist after ejecting only cards")
+	}
+
+	// Existing overrides are never overwritten
+	os.WriteFile(".craft/prompts/cards.tmpl", []byte("custom"), 0644)
+	Prompts([]string{"eject"})
+	data, _ := os.ReadFile(".craft/prompts/cards.tmpl")
+	if string(data) != "custom" {
+		t.Error("eject should not overwrite existing templates")
+	}
+	if _, err := os.Stat(".craft/prompts/review.tmpl"); err != nil {
+		t.Error(".craft/prompts/review.tmpl should exist after eject")
+	}
+
+	if code := Prompts([]string{"eject", "bogus"}); code != 1 {
+		t.Errorf("Prompts(eject bogus) = %d, want 1", code)
+	}
+}
+
+func TestStartWithBudget(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	code := Start([]string{"--budget=2.50", "Add rate limiting"})
+	if code != 0 {
+		t.Fatalf("Start() = %d, want 0", code)
+	}
+
+	w, _ := workflow.Load()
+	if w.Budget != 2.5 {
+		t.Errorf("Budget = %v, want 2.5", w.Budget)
+	}
+	if w.Intent != "Add rate limiting" {
+		t.Errorf("Intent = %q, want %q", w.Intent, "Add rate limiting")
+	}
+
+	if code := Start([]string{"--budget=lots", "Other"}); code != 1 {
+		t.Errorf("Start() with invalid budget = %d, want 1", code)
+	}
+}
+
+func TestBudgetCommand(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+
+	if code := Budget(nil); code != 0 {
+		t.Errorf("Budget() = %d, want 0", code)
+	}
+	if code := Budget([]string{"$5"}); code != 0 {
+		t.Errorf("Budget($5) = %d, want 0", code)
+	}
+	w, _ := workflow.Load()
+	if w.Budget != 5 {
+		t.Errorf("Budget = %v, want 5", w.Budget)
+	}
+	if last := w.History[len(w.History)-1]; last.Note != "Budget set to $5.00" {
+		t.Errorf("last history note = %q, want the budget recorded", last.Note)
+	}
+
+	Budget([]string{"10"})
+	if code := Undo(nil); code != 0 {
+		t.Fatalf("Undo() = %d, want 0", code)
+	}
+	if w, _ = workflow.Load(); w.Budget != 5 {
+		t.Errorf("Budget after undo = %v, want 5", w.Budget)
+	}
+
+	if code := Budget([]string{"none"}); code != 0 {
+		t.Errorf("Budget(none) = %d, want 0", code)
+	}
+	w, _ = workflow.Load()
+	if w.Budget != 0 {
+		t.Errorf("Budget = %v, want 0", w.Budget)
+	}
+
+	if code := Budget([]string{"-1"}); code != 1 {
+		t.Errorf("Budget(-1) = %d, want 1", code)
+	}
+}
+
+func TestReviewBlockedByBudget(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	os.Setenv("CRAFT_AI_API_KEY", "test-key")
+	defer os.Unsetenv("CRAFT_AI_API_KEY")
+
+	Start([]string{"Test"})
+	w, _ := workflow.Load()
+	w.Budget = 0.01
+	w.RecordUsage(usage.Call{Model: "gpt-4o", PromptTokens: 10000})
+	w.Save()
+
+	code := Think([]string{"--review=ai"})
+	if code != 1 {
+		t.Errorf("Think(--review=ai) over budget = %d, want 1", code)
+	}
+}
+
+func TestBudgetWithDatedModel(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	// APIs answer with a dated snapshot of the requested model
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"gpt-4o-mini-2024-07-18","choices":[{"message":{"content":"Who is it for?"}}],"usage":{"prompt_tokens":200000,"completion_tokens":100}}`))
+	}))
+	defer server.Close()
+	t.Setenv("CRAFT_AI_API_KEY", "test-key")
+	t.Setenv("CRAFT_AI_BASE_URL", server.URL)
+	t.Setenv("CRAFT_AI_MODEL", "gpt-4o-mini")
+
+	Start([]string{"--budget", "0.01", "Test"})
+	if code := Think([]string{"--review=ai"}); code != 0 {
+		t.Fatalf("Think(--review=ai) = %d, want 0", code)
+	}
+	if code := Think([]string{"--review=ai"}); code != 1 {
+		t.Errorf("Think(--review=ai) after spending $0.03 of $0.01 = %d, want 1", code)
+	}
+}
+
+func TestThinkReviewWithPlugin(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat >/dev/null\necho '{\"content\":\"Looks scoped.\"}'\n"
+	os.WriteFile(dir+"/craft-reviewer-team", []byte(script), 0755)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	Start([]string{"Test intent"})
+	if code := Think([]string{"--review=team"}); code != 0 {
+		t.Errorf("Think(--review=team) = %d, want 0", code)
+	}
+	reviews, _ := workflow.ListReviews()
+	if len(reviews) != 1 {
+		t.Fatalf("ListReviews() = %v, want one saved review", reviews)
+	}
+	if data, _ := os.ReadFile(reviews[0]); !strings.Contains(string(data), "Looks scoped.") {
+		t.Errorf("saved review = %q, want the reviewer's content", data)
+	}
+	if code := Think([]string{"--review=missing"}); code != 1 {
+		t.Errorf("Think(--review=missing) = %d, want 1", code)
+	}
+}
+
+func TestShapeGenerateWithPlugin(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat >/dev/null\necho '{\"pitch\":\"# Pitch: P\",\"cards\":[{\"content\":\"# Card: C\"}]}'\n"
+	os.WriteFile(dir+"/craft-shaper-team", []byte(script), 0755)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	Start([]string{"Test intent"})
+	Accept(nil)
+
+	if code := Shape([]string{"--generate=team"}); code != 0 {
+		t.Fatalf("Shape(--generate=team) = %d, want 0", code)
+	}
+	if _, err := os.Stat(".craft/cards/01-c.md"); err != nil {
+		t.Error(".craft/cards/01-c.md should exist after shaping")
+	}
+	if code := Shape([]string{"--generate=missing"}); code != 1 {
+		t.Errorf("Shape(--generate=missing) = %d, want 1", code)
+	}
+}
+
+func TestAcceptWithAppetite(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	if code := Accept([]string{"--appetite=2w", "looks good"}); code != 0 {
+		t.Fatalf("Accept() = %d, want 0", code)
+	}
+
+	w, _ := workflow.Load()
+	if w.Appetite != 14*24*time.Hour {
+		t.Errorf("Appetite = %s, want 2w", w.Appetite)
+	}
+	if len(w.Notes) != 1 || w.Notes[0].Text != "looks good" {
+		t.Errorf("Notes = %v, want [looks good]", w.Notes)
+	}
+}
+
+func TestAcceptInvalidAppetite(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	if code := Accept([]string{"--appetite=soon"}); code != 1 {
+		t.Errorf("Accept() = %d, want 1", code)
+	}
+
+	w, _ := workflow.Load()
+	if w.State != "thinking" {
+		t.Errorf("State = %s, want thinking", w.State)
+	}
+}
+
+func TestApproveWithAppetite(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	Accept([]string{"--appetite=2w"})
+	os.WriteFile(".craft/pitch.md", []byte("# Pitch"), 0644)
+
+	if code := Approve([]string{"--appetite=1w"}); code != 0 {
+		t.Fatalf("Approve() = %d, want 0", code)
+	}
+
+	w, _ := workflow.Load()
+	if w.Appetite != 7*24*time.Hour {
+		t.Errorf("Appetite = %s, want 1w", w.Appetite)
+	}
+}
+
+func TestCheckAppetite(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	if code := Check(nil); code != 0 {
+		t.Errorf("Check() without appetite = %d, want 0", code)
+	}
+
+	Accept([]string{"--skip-shaping", "--appetite=1d"})
+	if code := Check(nil); code != 0 {
+		t.Errorf("Check() within appetite = %d, want 0", code)
+	}
+
+	// Pretend building started two days ago
+	w, _ := workflow.Load()
+	w.History[len(w.History)-1].At = time.Now().Add(-48 * time.Hour).UTC()
+	w.Save()
+
+	if code := Check(nil); code != 1 {
+		t.Errorf("Check() over appetite = %d, want 1", code)
+	}
+	if code := Status(nil); code != 0 {
+		t.Errorf("Status() = %d, want 0", code)
+	}
+
+	if code := Appetite([]string{"3d"}); code != 0 {
+		t.Errorf("Appetite(3d) = %d, want 0", code)
+	}
+	if code := Check(nil); code != 0 {
+		t.Errorf("Check() after extending = %d, want 0", code)
+	}
+	w, _ = workflow.Load()
+	if last := w.History[len(w.History)-1]; last.Note != "Appetite extended to 3d (was 1d)" {
+		t.Errorf("last history note = %q, want the extension recorded", last.Note)
+	}
+
+	// Extending can be undone like any other decision
+	if code := Undo(nil); code != 0 {
+		t.Fatalf("Undo() = %d, want 0", code)
+	}
+	if w, _ = workflow.Load(); w.Appetite != 24*time.Hour {
+		t.Errorf("Appetite after undo = %s, want 1d", w.Appetite)
+	}
+	Appetite([]string{"3d"})
+
+	if code := Appetite([]string{"none"}); code != 0 {
+		t.Errorf("Appetite(none) = %d, want 0", code)
+	}
+	w, _ = workflow.Load()
+	if w.Appetite != 0 {
+		t.Errorf("Appetite = %s, want none", w.Appetite)
+	}
+}
+
+func TestResetArchivesWorkflow(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Abandon me"})
+	workflow.SaveReview("Heuristic", "Too vague")
+	if code := Reset([]string{"--force"}); code != 0 {
+		t.Fatalf("Reset() = %d, want 0", code)
+	}
+	if reviews, _ := workflow.ListReviews(); len(reviews) != 0 {
+		t.Errorf("ListReviews() = %v after reset, want the abandoned workflow's reviews removed", reviews)
+	}
+
+	archived, err := workflow.LoadArchive()
+	if err != nil {
+		t.Fatalf("LoadArchive() error = %v", err)
+	}
+	if len(archived) != 1 {
+		t.Fatalf("archived %d workflows, want 1", len(archived))
+	}
+	w := archived[0]
+	if w.Intent != "Abandon me" {
+		t.Errorf("Intent = %q, want %q", w.Intent, "Abandon me")
+	}
+	if last := w.History[len(w.History)-1]; last.Note != workflow.NoteAbandoned {
+		t.Errorf("last history note = %q, want %q", last.Note, workflow.NoteAbandoned)
+	}
+}
+
+func TestStats(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	if code := Stats(nil); code != 0 {
+		t.Errorf("Stats() with no workflows = %d, want 0", code)
+	}
+
+	Start([]string{"First"})
+	Accept([]string{"--skip-shaping"})
+	Ship(nil)
+	Reset([]string{"--force"})
+	Start([]string{"Second"})
+	Reset([]string{"--force"})
+
+	for _, args := range [][]string{nil, {"--json"}, {"--openmetrics"}} {
+		if code := Stats(args); code != 0 {
+			t.Errorf("Stats(%v) = %d, want 0", args, code)
+		}
+	}
+	if code := Stats([]string{"--csv"}); code != 1 {
+		t.Errorf("Stats(--csv) = %d, want 1", code)
+	}
+}
+
+func TestReviseRecordsHistory(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	Accept(nil)
+	Revise([]string{"Cards too big"})
+
+	w, _ := workflow.Load()
+	if last := w.History[len(w.History)-1]; last.Note != "Revised: Cards too big" {
+		t.Errorf("last history note = %q, want %q", last.Note, "Revised: Cards too big")
+	}
+}
+
+func TestRejectBlocksAccept(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	Reject([]string{"Who is this for?"})
+	Reject([]string{"What about mobile?"})
+
+	if code := Accept(nil); code != 1 {
+		t.Errorf("Accept() with open concerns = %d, want 1", code)
+	}
+
+	if code := Resolve([]string{"C1", "Admins"}); code != 0 {
+		t.Errorf("Resolve(C1) = %d, want 0", code)
+	}
+	if code := Accept(nil); code != 1 {
+		t.Errorf("Accept() with one open concern = %d, want 1", code)
+	}
+
+	if code := Resolve([]string{"c2", "--waive"}); code != 1 {
+		t.Errorf("Resolve(--waive) without reason = %d, want 1", code)
+	}
+	if code := Resolve([]string{"c2", "--waive", "Out of scope"}); code != 0 {
+		t.Errorf("Resolve(c2 --waive) = %d, want 0", code)
+	}
+	if code := Accept(nil); code != 0 {
+		t.Errorf("Accept() after resolving = %d, want 0", code)
+	}
+
+	w, _ := workflow.Load()
+	if w.Concerns[0].Status != workflow.ConcernResolved || w.Concerns[0].Answer != "Admins" {
+		t.Errorf("C1 = %+v, want resolved with answer", w.Concerns[0])
+	}
+	if w.Concerns[1].Status != workflow.ConcernWaived || w.Concerns[1].Answer != "Out of scope" {
+		t.Errorf("C2 = %+v, want waived with reason", w.Concerns[1])
+	}
+}
+
+func TestReviseBlocksApprove(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	Accept(nil)
+	os.WriteFile(".craft/pitch.md", []byte("# Pitch"), 0644)
+	Revise([]string{"Card 2 is too big"})
+
+	if code := Approve(nil); code != 1 {
+		t.Errorf("Approve() with open concern = %d, want 1", code)
+	}
+	if code := Resolve([]string{"C1", "Split into 2a and 2b"}); code != 0 {
+		t.Errorf("Resolve() = %d, want 0", code)
+	}
+	if code := Approve(nil); code != 0 {
+		t.Errorf("Approve() after resolving = %d, want 0", code)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	Reject([]string{"Why now?"})
+
+	if code := Resolve([]string{"C1"}); code != 1 {
+		t.Errorf("Resolve() without answer = %d, want 1", code)
+	}
+	if code := Resolve([]string{"C9", "Answer"}); code != 1 {
+		t.Errorf("Resolve(C9) = %d, want 1", code)
+	}
+	Resolve([]string{"C1", "Deadline"})
+	if code := Resolve([]string{"C1", "Again"}); code != 1 {
+		t.Errorf("Resolve() twice = %d, want 1", code)
+	}
+}
+
+func TestNoteCommand(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	if code := Note([]string{"orphan"}); code != 1 {
+		t.Errorf("Note() without workflow = %d, want 1", code)
+	}
+
+	Start([]string{"Test"})
+	Accept([]string{"--skip-shaping"})
+
+	if code := Note([]string{"--type=decision", "Reuse the existing cache"}); code != 0 {
+		t.Errorf("Note(decision) = %d, want 0", code)
+	}
+	if code := Note([]string{"Remember to update docs"}); code != 0 {
+		t.Errorf("Note() = %d, want 0", code)
+	}
+	if code := Note([]string{"--type=idea", "Nope"}); code != 1 {
+		t.Errorf("Note(--type=idea) = %d, want 1", code)
+	}
+	if code := Note([]string{"--type=risk"}); code != 1 {
+		t.Errorf("Note() without text = %d, want 1", code)
+	}
+
+	w, _ := workflow.Load()
+	if len(w.Notes) != 2 {
+		t.Fatalf("Notes = %+v, want 2", w.Notes)
+	}
+	if n := w.Notes[0]; n.Type != workflow.NoteDecision || n.Phase != "building" || n.At.IsZero() {
+		t.Errorf("Notes[0] = %+v, want decision recorded while building", n)
+	}
+	if w.Notes[1].Type != workflow.NoteGeneral {
+		t.Errorf("Notes[1].Type = %q, want note", w.Notes[1].Type)
+	}
+
+	if code := Status(nil); code != 0 {
+		t.Errorf("Status() = %d, want 0", code)
+	}
+	if code := Think(nil); code != 0 {
+		t.Errorf("Think() = %d, want 0", code)
+	}
+
+	Ship(nil)
+	if code := Note([]string{"Too late"}); code != 1 {
+		t.Errorf("Note() after shipping = %d, want 1", code)
+	}
+}
+
+func TestUndoAccept(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	if code := Undo(nil); code != 1 {
+		t.Errorf("Undo() with nothing to undo = %d, want 1", code)
+	}
+
+	Note([]string{"--type=risk", "Might be slow"})
+	Accept([]string{"Oops"})
+
+	if code := Undo(nil); code != 0 {
+		t.Fatalf("Undo() = %d, want 0", code)
+	}
+	w, _ := workflow.Load()
+	if w.State != "thinking" {
+		t.Errorf("State = %s, want thinking", w.State)
+	}
+	if len(w.Notes) != 1 || w.Notes[0].Text != "Might be slow" {
+		t.Errorf("Notes = %+v, want only the risk", w.Notes)
+	}
+	if last := w.History[len(w.History)-1]; last.Note != "Undid accept" {
+		t.Errorf("last history note = %q, want %q", last.Note, "Undid accept")
+	}
+
+	// Undo again reverts the note
+	if code := Undo(nil); code != 0 {
+		t.Fatalf("Undo() = %d, want 0", code)
+	}
+	w, _ = workflow.Load()
+	if len(w.Notes) != 0 {
+		t.Errorf("Notes = %+v, want none", w.Notes)
+	}
+}
+
+func TestUndoOutsideGrace(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	Accept(nil)
+	os.WriteFile(".craft/config.toml", []byte("[undo]\ngrace = \"1ns\"\n"), 0644)
+	time.Sleep(time.Millisecond)
+
+	if code := Undo(nil); code != 1 {
+		t.Errorf("Undo() outside grace = %d, want 1", code)
+	}
+	w, _ := workflow.Load()
+	if w.State != "shaping" {
+		t.Errorf("State = %s, want shaping", w.State)
+	}
+}
+
+func TestStartClearsUndo(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"First"})
+	Accept(nil)
+	Reset([]string{"--force"})
+	Start([]string{"Second"})
+
+	if code := Undo(nil); code != 1 {
+		t.Errorf("Undo() into a previous workflow = %d, want 1", code)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		cmd      *Command
+		args     []string
+		flags    map[string]string
+		wantArgs []string
+	}{
+		{acceptCommand, []string{"--skip-shaping", "looks", "good"}, map[string]string{"skip-shaping": ""}, []string{"looks", "good"}},
+		{acceptCommand, []string{"ok", "--appetite", "2w"}, map[string]string{"appetite": "2w"}, []string{"ok"}},
+		{acceptCommand, []string{"--appetite=3d"}, map[string]string{"appetite": "3d"}, nil},
+		{acceptCommand, []string{"--", "--skip-shaping"}, map[string]string{}, []string{"--skip-shaping"}},
+		{thinkCommand, []string{"--review", "ai"}, map[string]string{"review": "ai"}, nil},
+		{thinkCommand, []string{"--review"}, map[string]string{"review": ""}, nil},
+		{shapeCommand, []string{"--generate=team"}, map[string]string{"generate": "team"}, nil},
+		{resetCommand, []string{"-f"}, map[string]string{"force": ""}, nil},
+		{noteCommand, []string{"--type", "risk", "-"}, map[string]string{"type": "risk"}, []string{"-"}},
+		{noteCommand, []string{"-- see above"}, map[string]string{}, []string{"-- see above"}},
+		{noteCommand, []string{"--type", "risk", "-5% latency"}, map[string]string{"type": "risk"}, []string{"-5% latency"}},
+		{noteCommand, []string{"--", "-v", "--type"}, map[string]string{}, []string{"-v", "--type"}},
+		{startCommand, []string{"-5% checkout latency"}, map[string]string{}, []string{"-5% checkout latency"}},
+	}
+
+	for _, tt := range tests {
+		p, err := tt.cmd.Parse(tt.args)
+		if err != nil {
+			t.Errorf("%s.Parse(%q) error = %v", tt.cmd.Name, tt.args, err)
+			continue
+		}
+		for name, value := range tt.flags {
+			if !p.Bool(name) || p.String(name) != value {
+				t.Errorf("%s.Parse(%q) --%s = %v %q, want %q", tt.cmd.Name, tt.args, name, p.Bool(name), p.String(name), value)
+			}
+		}
+		if strings.Join(p.Args, "|") != strings.Join(tt.wantArgs, "|") {
+			t.Errorf("%s.Parse(%q) args = %q, want %q", tt.cmd.Name, tt.args, p.Args, tt.wantArgs)
+		}
+	}
+}
+
+func TestParseFlagsErrors(t *testing.T) {
+	tests := []struct {
+		cmd  *Command
+		args []string
+	}{
+		{acceptCommand, []string{"--skip-shapping"}},
+		{acceptCommand, []string{"--skip-shaping=yes"}},
+		{acceptCommand, []string{"--appetite"}},
+		{shipCommand, []string{"now"}},
+		{resetCommand, []string{"-x"}},
+		{noteCommand, []string{"--verbose", "text"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.cmd.Parse(tt.args); err == nil {
+			t.Errorf("%s.Parse(%q) should fail", tt.cmd.Name, tt.args)
+		}
+	}
+}
+
+func TestAcceptUnknownFlag(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+
+	if code := Accept([]string{"--skip-shapping"}); code != 1 {
+		t.Errorf("Accept(--skip-shapping) = %d, want 1", code)
+	}
+
+	w, _ := workflow.Load()
+	if w.State != "thinking" {
+		t.Errorf("State = %s, want thinking", w.State)
+	}
+	if len(w.Notes) != 0 {
+		t.Errorf("Notes = %v, want none", w.Notes)
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+
+	if code := Accept([]string{"--help"}); code != 0 {
+		t.Errorf("Accept(--help) = %d, want 0", code)
+	}
+	w, _ := workflow.Load()
+	if w.State != "thinking" {
+		t.Errorf("State = %s, want thinking", w.State)
+	}
+
+	var buf bytes.Buffer
+	acceptCommand.WriteHelp(&buf)
+	for _, want := range []string{"Usage: craft accept [flags] [note]", "--skip-shaping", "--appetite=<d>"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("accept help missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestCommandsRegistered(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range Commands {
+		if seen[c.Name] {
+			t.Errorf("command %s registered twice", c.Name)
+		}
+		seen[c.Name] = true
+		if c.Summary == "" || c.Run == nil {
+			t.Errorf("command %s needs a summary and a Run func", c.Name)
+		}
+		if Lookup(c.Name) != c {
+			t.Errorf("Lookup(%s) did not return the command", c.Name)
+		}
+	}
+}
+
+func TestCompleteCommandsFollowState(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	has := func(list []string, s string) bool {
+		for _, v := range list {
+			if v == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	got := complete([]string{""})
+	if !has(got, "start") || has(got, "accept") {
+		t.Errorf("complete() without workflow = %v, want start but not accept", got)
+	}
+
+	Start([]string{"Test"})
+	got = complete([]string{""})
+	if has(got, "start") || !has(got, "accept") || has(got, "approve") || has(got, "ship") {
+		t.Errorf("complete() in thinking = %v, want accept but not start, approve or ship", got)
+	}
+	if has(got, "__complete") {
+		t.Errorf("complete() = %v, should not offer hidden commands", got)
+	}
+
+	Accept(nil)
+	got = complete([]string{"a"})
+	if strings.Join(got, " ") != "approve appetite" {
+		t.Errorf("complete(a) in shaping = %v, want [approve appetite]", got)
+	}
+}
+
+func TestCompleteWithDir(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	t.Setenv(workflow.EnvDir, "")
+
+	os.MkdirAll("pkg", 0755)
+	os.Setenv(workflow.EnvDir, "pkg")
+	Start([]string{"Package work"})
+	Accept(nil)
+	os.Setenv(workflow.EnvDir, "")
+
+	for _, words := range [][]string{
+		{"--dir=pkg", "ap"},
+		{"--dir", "pkg", "ap"},
+	} {
+		os.Setenv(workflow.EnvDir, "")
+		if got := complete(words); strings.Join(got, " ") != "approve appetite" {
+			t.Errorf("complete(%q) = %v, want the package's shaping commands", words, got)
+		}
+	}
+
+	// The flag's own value is still being typed
+	os.Setenv(workflow.EnvDir, "")
+	if got := complete([]string{"--dir=pk"}); len(got) != 0 {
+		t.Errorf("complete(--dir=pk) = %v, want no commands", got)
+	}
+}
+
+func TestCompleteFlagsAndValues(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"accept", "--sk"}, "--skip-shaping"},
+		{[]string{"think", "--review=h"}, "--review=heuristic"},
+		{[]string{"think", "--review", "cou"}, "council"},
+		{[]string{"note", "--type=d"}, "--type=decision"},
+		{[]string{"shape", "--generate="}, "--generate=shape-cli --generate=ai"},
+		{[]string{"prompts", "s"}, "show"},
+		{[]string{"prompts", "show", "p"}, "pitch"},
+		{[]string{"completion", "f"}, "fish"},
+		{[]string{"help", "sta"}, "start status stats"},
+		{[]string{"unknown", ""}, ""},
+	}
+
+	for _, tt := range tests {
+		got := strings.Join(complete(tt.words), " ")
+		if got != tt.want {
+			t.Errorf("complete(%q) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range completionShells {
+		if code := Completion([]string{shell}); code != 0 {
+			t.Errorf("Completion(%s) = %d, want 0", shell, code)
+		}
+	}
+	if code := Completion([]string{"powershell"}); code != 1 {
+		t.Errorf("Completion(powershell) = %d, want 1", code)
+	}
+}
+
+func TestCommandsFromSubdirectory(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Test"})
+	os.MkdirAll("internal/foo", 0755)
+	os.Chdir("internal/foo")
+
+	if code := Start([]string{"Second"}); code != 1 {
+		t.Errorf("Start() from subdirectory = %d, want 1", code)
+	}
+	if _, err := os.Stat(".craft"); err == nil {
+		t.Error("Start() should not create .craft in a subdirectory")
+	}
+
+	if code := Accept(nil); code != 0 {
+		t.Errorf("Accept() from subdirectory = %d, want 0", code)
+	}
+	w, _ := workflow.Load()
+	if w == nil || w.State != "shaping" {
+		t.Errorf("workflow not advanced from subdirectory: %+v", w)
+	}
+}
+
+func TestStatusAll(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	os.MkdirAll(".git", 0755)
+	os.MkdirAll("services/api", 0755)
+	Start([]string{"Root work"})
+
+	t.Setenv(workflow.EnvDir, "services/api")
+	Start([]string{"API work"})
+	Accept(nil)
+
+	if w, _ := workflow.Load(); w == nil || w.Intent != "API work" {
+		t.Fatalf("CRAFT_DIR workflow = %+v, want API work", w)
+	}
+	if _, err := os.Stat("services/api/.craft/workflow.md"); err != nil {
+		t.Errorf("CRAFT_DIR workflow not created in package: %v", err)
+	}
+
+	// Started before the registry existed, or with another tool
+	os.MkdirAll("libs/util", 0755)
+	t.Setenv(workflow.EnvDir, "libs/util")
+	workflow.New("Unregistered work").Save()
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	code := Status([]string{"--all"})
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	out := buf.String()
+
+	if code != 0 {
+		t.Errorf("Status(--all) = %d, want 0", code)
+	}
+	for _, want := range []string{"Root work", "services/api", "shaping", "API work", "libs/util", "Unregistered work"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Status(--all) missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestConfigCommand(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	dir, _ := os.Getwd()
+	t.Setenv("XDG_CONFIG_HOME", dir+"/home")
+	t.Setenv("CRAFT_AI_MODEL", "")
+
+	if code := Config([]string{"set", "ai.model", "gpt-test"}); code != 0 {
+		t.Errorf("Config(set) = %d, want 0", code)
+	}
+	if code := Config([]string{"set", "ai.api_key", "sk-test"}); code != 1 {
+		t.Errorf("Config(set ai.api_key) in project = %d, want 1", code)
+	}
+	if code := Config([]string{"set", "--user", "ai.api_key", "sk-test"}); code != 0 {
+		t.Errorf("Config(set --user ai.api_key) = %d, want 0", code)
+	}
+	if _, err := os.Stat(dir + "/home/craft/config.toml"); err != nil {
+		t.Errorf("user config not written: %v", err)
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	Config([]string{"list", "--show-origin"})
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	out := buf.String()
+
+	if !strings.Contains(out, ".craft/config.toml\tai.model = gpt-test") {
+		t.Errorf("config list missing project origin:\n%s", out)
+	}
+	if strings.Contains(out, "sk-test") {
+		t.Errorf("config list should mask secrets:\n%s", out)
+	}
+
+	if code := Config([]string{"get", "missing.key"}); code != 1 {
+		t.Errorf("Config(get missing.key) = %d, want 1", code)
+	}
+	if code := Config([]string{"bogus"}); code != 1 {
+		t.Errorf("Config(bogus) = %d, want 1", code)
+	}
+}
+
+func TestConfigGetMasksSecret(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	if code := Config([]string{"set", "--user", "ai.api_key", "sk-test"}); code != 0 {
+		t.Fatalf("Config(set --user ai.api_key) = %d, want 0", code)
+	}
+
+	get := func(args ...string) string {
+		old := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		Config(append([]string{"get"}, args...))
+
+		w.Close()
+		os.Stdout = old
+
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		return strings.TrimSpace(buf.String())
+	}
+
+	if out := get("ai.api_key"); out != maskedSecret {
+		t.Errorf("config get ai.api_key = %q, want it masked", out)
+	}
+	if out := get("--reveal", "ai.api_key"); out != "sk-test" {
+		t.Errorf("config get --reveal ai.api_key = %q, want sk-test", out)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	bundlePath := t.TempDir() + "/handoff.tar.gz"
+	if code := Export([]string{"--bundle", bundlePath}); code != 1 {
+		t.Errorf("Export() without workflow = %d, want 1", code)
+	}
+
+	Start([]string{"Hand this off"})
+	Accept(nil)
+	if code := Export([]string{"--bundle", bundlePath}); code != 0 {
+		t.Fatalf("Export(--bundle) = %d, want 0", code)
+	}
+	if code := Export(nil); code != 1 {
+		t.Errorf("Export() without --bundle = %d, want 1", code)
+	}
+
+	// An active workflow is only replaced with --replace
+	if code := Import([]string{bundlePath}); code != 1 {
+		t.Errorf("Import() over active workflow = %d, want 1", code)
+	}
+	if code := Import([]string{"--replace", bundlePath}); code != 0 {
+		t.Errorf("Import(--replace) = %d, want 0", code)
+	}
+	if archived, _ := workflow.LoadArchive(); len(archived) != 1 {
+		t.Errorf("LoadArchive() = %d workflows, want the replaced one archived", len(archived))
+	}
+
+	// On another machine
+	os.Chdir(t.TempDir())
+	if code := Import([]string{bundlePath}); code != 0 {
+		t.Fatalf("Import() = %d, want 0", code)
+	}
+	w, err := workflow.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if w.Intent != "Hand this off" || w.State != "shaping" {
+		t.Errorf("imported workflow = %q in %s, want the exported one", w.Intent, w.State)
+	}
+	last := w.History[len(w.History)-1]
+	if !strings.HasPrefix(last.Note, "Imported from handoff.tar.gz") {
+		t.Errorf("last history note = %q, want the import recorded", last.Note)
+	}
+	if err := w.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() = %v", err)
+	}
+
+	os.WriteFile("corrupt.tar.gz", []byte("not a bundle"), 0644)
+	if code := Import([]string{"--replace", "corrupt.tar.gz"}); code != 1 {
+		t.Errorf("Import(corrupt) = %d, want 1", code)
+	}
+}
+
+func TestExportIssues(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	dir, _ := os.Getwd()
+	t.Setenv("XDG_CONFIG_HOME", dir+"/home")
+	t.Setenv("GITHUB_TOKEN", "test-token")
+
+	created := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			created++
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(`{"html_url": "https://github.com/acme/api/issues/` + strconv.Itoa(created) + `"}`))
+	}))
+	defer server.Close()
+
+	if code := Export([]string{"--issues", "github"}); code != 1 {
+		t.Errorf("Export(--issues) without workflow = %d, want 1", code)
+	}
+	Start([]string{"Add rate limiting"})
+	if code := Export([]string{"--issues", "github"}); code != 1 {
+		t.Errorf("Export(--issues) without cards = %d, want 1", code)
+	}
+
+	os.MkdirAll(".craft/cards", 0755)
+	os.WriteFile(".craft/cards/01-limiter.md", []byte("# Card: Add limiter\n"), 0644)
+	if code := Export([]string{"--issues", "github"}); code != 1 {
+		t.Errorf("Export(--issues) without repo = %d, want 1", code)
+	}
+	Config([]string{"set", "issues.github.repo", "acme/api"})
+	Config([]string{"set", "--user", "issues.github.api_url", server.URL})
+	if code := Export([]string{"--issues", "jira"}); code != 1 {
+		t.Errorf("Export(--issues jira) = %d, want 1", code)
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	first := Export([]string{"--issues", "github"})
+	second := Export([]string{"--issues=github"})
+	w.Close()
+	os.Stdout = old
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	out := buf.String()
+
+	if first != 0 || second != 0 {
+		t.Fatalf("Export(--issues) = %d, %d, want 0", first, second)
+	}
+	if created != 1 {
+		t.Errorf("created %d issues, want 1 and then an update", created)
+	}
+	for _, want := range []string{
+		"Created 01-limiter.md: https://github.com/acme/api/issues/1",
+		"Updated 01-limiter.md: https://github.com/acme/api/issues/1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// setIdentity makes identity.Current return name, whatever git is
+// configured with on this machine.
+func setIdentity(t *testing.T, name string) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "user.name")
+	t.Setenv("GIT_CONFIG_VALUE_0", name)
+}
+
+func TestHandoff(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	setIdentity(t, "Alice")
+
+	if code := Handoff([]string{"Bob", "context"}); code != 1 {
+		t.Errorf("Handoff() without workflow = %d, want 1", code)
+	}
+	Start([]string{"Share the work"})
+	if code := Handoff([]string{"Bob"}); code != 1 {
+		t.Errorf("Handoff() without context = %d, want 1", code)
+	}
+	if code := Handoff([]string{"Bob", "Intent agreed, accept when ready"}); code != 0 {
+		t.Fatalf("Handoff() = %d, want 0", code)
+	}
+
+	setIdentity(t, "Bob")
+	Accept(nil)
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	Status(nil)
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	out := buf.String()
+
+	for _, want := range []string{
+		"Owner: Bob\n",
+		"Contributors: Alice\n",
+		`"Handed off from Alice to Bob: Intent agreed, accept when ready" (Alice)`,
+		"shaping (Bob)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Status() output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestTwoPersonRule(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	setIdentity(t, "Alice")
+
+	Start([]string{"--two-person", "Rotate the signing keys"})
+	Accept(nil)
+	os.WriteFile(".craft/pitch.md", []byte("# Pitch\n"), 0644)
+
+	if code := Approve(nil); code != 1 {
+		t.Errorf("Approve() by the accepter = %d, want 1", code)
+	}
+	setIdentity(t, "Bob")
+	if code := Approve(nil); code != 0 {
+		t.Fatalf("Approve() by someone else = %d, want 0", code)
+	}
+
+	setIdentity(t, "alice")
+	if code := Ship(nil); code != 1 {
+		t.Errorf("Ship() by the accepter = %d, want 1", code)
+	}
+	setIdentity(t, "Carol")
+	if code := Ship(nil); code != 0 {
+		t.Fatalf("Ship() by someone else = %d, want 0", code)
+	}
+
+	w, _ := workflow.Load()
+	if !w.TwoPerson {
+		t.Error("TwoPerson = false, want it recorded at start")
+	}
+	n := len(w.History)
+	approve, ship := w.History[n-2], w.History[n-1]
+	if approve.Actor != "Bob" || approve.Note != "Two-person rule: accepted by Alice" {
+		t.Errorf("approve history = %+v", approve)
+	}
+	if ship.Actor != "Carol" || ship.Note != "Two-person rule: accepted by Alice" {
+		t.Errorf("ship history = %+v", ship)
+	}
+}
+
+func TestTwoPersonRuleFromProjectConfig(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	setIdentity(t, "Alice")
+
+	Start([]string{"Skip shaping"})
+	Accept([]string{"--skip-shaping"})
+	if code := Ship(nil); code != 0 {
+		t.Fatalf("Ship() without the rule = %d, want 0", code)
+	}
+	Reset([]string{"--force"})
+
+	os.WriteFile(".craft/config.toml", []byte("[workflow]\ntwo_person = true\n"), 0644)
+	Start([]string{"Skip shaping again"})
+	Accept([]string{"--skip-shaping"})
+	if code := Ship(nil); code != 1 {
+		t.Errorf("Ship() by the accepter under project rule = %d, want 1", code)
+	}
+	if w, _ := workflow.Load(); !w.TwoPerson {
+		t.Error("TwoPerson = false, want the project rule recorded at start")
+	}
+}
+
+func TestStartIntentSources(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	intent := "Add rate limiting\n\n- Done when clients get 429\n- Must not break existing keys"
+
+	os.WriteFile("intent.md", []byte(intent+"\n"), 0644)
+	if code := Start([]string{"--file", "intent.md"}); code != 0 {
+		t.Fatalf("Start(--file) = %d, want 0", code)
+	}
+	if w, _ := workflow.Load(); w.Intent != intent {
+		t.Errorf("Intent from file = %q, want %q", w.Intent, intent)
+	}
+	Reset([]string{"--force"})
+
+	oldStdin := stdinReader
+	stdinReader = strings.NewReader(intent)
+	defer func() { stdinReader = oldStdin }()
+	if code := Start([]string{"-"}); code != 0 {
+		t.Fatalf("Start(-) = %d, want 0", code)
+	}
+	if w, _ := workflow.Load(); w.Intent != intent {
+		t.Errorf("Intent from stdin = %q, want %q", w.Intent, intent)
+	}
+	Reset([]string{"--force"})
+
+	// The editor appends to the template, whose comment is dropped
+	editor := t.TempDir() + "/editor"
+	os.WriteFile(editor, []byte("#!/bin/sh\nprintf 'Edited intent\\n\\nWith context\\n' >> \"$1\"\n"), 0755)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+	if code := Start([]string{"--edit"}); code != 0 {
+		t.Fatalf("Start(--edit) = %d, want 0", code)
+	}
+	if w, _ := workflow.Load(); w.Intent != "Edited intent\n\nWith context" {
+		t.Errorf("Intent from editor = %q", w.Intent)
+	}
+	Reset([]string{"--force"})
+
+	t.Setenv("EDITOR", "true")
+	if code := Start([]string{"--edit"}); code != 1 {
+		t.Errorf("Start(--edit) with an empty intent = %d, want 1", code)
+	}
+	if code := Start([]string{"--file", "intent.md", "Also args"}); code != 1 {
+		t.Errorf("Start() with two sources = %d, want 1", code)
+	}
+	if code := Start([]string{"--file", "missing.md"}); code != 1 {
+		t.Errorf("Start(--file missing) = %d, want 1", code)
+	}
+	if workflow.Exists() {
+		t.Error("failed starts should not create a workflow")
+	}
+}
+
+func TestIntentRevisions(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	Start([]string{"Add rate limiting"})
+	if code := Intent([]string{"set", "Add rate limiting to the API"}); code != 1 {
+		t.Errorf("Intent(set) without --reason = %d, want 1", code)
+	}
+	if code := Intent([]string{"set", "--reason", "Name the API", "Add rate limiting to the public API"}); code != 0 {
+		t.Fatalf("Intent(set) = %d, want 0", code)
+	}
+
+	editor := t.TempDir() + "/editor"
+	os.WriteFile(editor, []byte("#!/bin/sh\nprintf '\\n- Done when clients get 429\\n' > \"$1.tmp\"\ncat \"$1\" >> \"$1.tmp\"\nmv \"$1.tmp\" \"$1\"\n"), 0755)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+	if code := Intent([]string{"edit", "--reason=Add criteria"}); code != 0 {
+		t.Fatalf("Intent(edit) = %d, want 0", code)
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	Think(nil)
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	out := buf.String()
+	for _, want := range []string{"r1 ", ": Name the API\n", "r2 ", ": Add criteria\n", "Changes in r2:\n+ - Done when clients get 429\n  Add rate limiting to the public API\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Think() output missing %q:\n%s", want, out)
+		}
+	}
+
+	Accept(nil)
+	wf, _ := workflow.Load()
+	if wf.FrozenIntent != wf.HashIntent() || len(wf.Revisions) != 2 {
+		t.Errorf("after accept FrozenIntent = %q, revisions = %d", wf.FrozenIntent, len(wf.Revisions))
+	}
+	if code := Intent([]string{"set", "--reason", "late", "Something else"}); code != 1 {
+		t.Errorf("Intent(set) after accept = %d, want 1", code)
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import "io"
+
+// Commands lists every command in the order shown by `craft --help`.
+var Commands []*Command
+
+// Commands is filled in by init rather than its declaration because
+// completion reads it, which would otherwise be an initialization cycle.
+func init() {
+	Commands = []*Command{
+		startCommand,
+		thinkCommand,
+		intentCommand,
+		acceptCommand,
+		rejectCommand,
+		shapeCommand,
+		approveCommand,
+		reviseCommand,
+		resolveCommand,
+		noteCommand,
+		handoffCommand,
+		shipCommand,
+		undoCommand,
+		statusCommand,
+		budgetCommand,
+		appetiteCommand,
+		checkCommand,
+		statsCommand,
+		resetCommand,
+		exportCommand,
+		importCommand,
+		configCommand,
+		initCommand,
+		promptsCommand,
+		completionCommand,
+		completeCommand,
+	}
+}
+
+// Lookup returns the command with the given name, or nil.
+func Lookup(name string) *Command {
+	for _, c := range Commands {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// WriteSummary writes the command's line, and any extra usage forms, for
+// the command list in `craft --help`.
+func (c *Command) WriteSummary(w io.Writer) {
+	name := c.Name
+	if c.Args != "" {
+		name += " " + c.Args
+	}
+	writeHelpLine(w, name, c.Summary)
+	for _, form := range c.Forms {
+		writeHelpLine(w, c.Name+" "+form.Args, form.Summary)
+	}
+}
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"craft/internal/config"
+	"craft/internal/issues"
+	"craft/internal/prompts"
+	"craft/internal/reviewer"
+	"craft/internal/shaper"
+	"craft/internal/state"
+	"craft/internal/workflow"
+)
+
+// Shells that completion scripts can be generated for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// transitionCommands are only offered for completion when
+// state.NextValidActions allows them in the current state.
+var transitionCommands = map[string]bool{
+	"accept":  true,
+	"reject":  true,
+	"shape":   true,
+	"approve": true,
+	"revise":  true,
+	"ship":    true,
+	"reset":   true,
+}
+
+// workflowlessCommands are offered for completion when there is no workflow.
+var workflowlessCommands = map[string]bool{
+	"start":      true,
+	"status":     true,
+	"stats":      true,
+	"init":       true,
+	"prompts":    true,
+	"config":     true,
+	"import":     true,
+	"completion": true,
+}
+
+var completionCommand = &Command{
+	Name:    "completion",
+	Args:    "<shell>",
+	Summary: "Print a completion script for bash, zsh or fish",
+	Run:     runCompletion,
+}
+
+var completeCommand = &Command{
+	Name:    "__complete",
+	Args:    "[words...]",
+	Summary: "List completions for the words typed so far",
+	Hidden:  true,
+	Raw:     true,
+	Run:     runComplete,
+}
+
+// Completion prints a shell completion script.
+func Completion(args []string) int {
+	return completionCommand.Execute(args)
+}
+
+func runCompletion(p *Parsed) int {
+	if len(p.Args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: Shell required. Usage: craft completion bash|zsh|fish")
+		return 1
+	}
+
+	switch p.Args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unsupported shell '%s'. Use bash, zsh or fish.\n", p.Args[0])
+		return 1
+	}
+	return 0
+}
+
+// runComplete prints one candidate per line for the words after "craft";
+// the last word is the one being completed and may be empty.
+func runComplete(p *Parsed) int {
+	for _, c := range complete(p.Args) {
+		fmt.Println(c)
+	}
+	return 0
+}
+
+// complete returns candidates for the last of words that start with it.
+func complete(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	// Apply --dir before the command, so candidates follow its workflow.
+	// The flag is only consumed once a word follows it and its value.
+	for {
+		if dir, ok := strings.CutPrefix(words[0], "--dir="); ok && len(words) > 1 {
+			os.Setenv(workflow.EnvDir, dir)
+			words = words[1:]
+		} else if words[0] == "--dir" && len(words) > 2 {
+			os.Setenv(workflow.EnvDir, words[1])
+			words = words[2:]
+		} else {
+			break
+		}
+	}
+	cur := words[len(words)-1]
+	prev := words[:len(words)-1]
+
+	var candidates []string
+	switch {
+	case len(prev) == 0:
+		candidates = completeCommands()
+	case prev[0] == "help":
+		if len(prev) == 1 {
+			candidates = completeCommands()
+		}
+	default:
+		c := Lookup(prev[0])
+		if c == nil || c.Hidden {
+			return nil
+		}
+		candidates = completeArgs(c, prev[1:], cur)
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, cur) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// completeCommands lists the commands that make sense now: state-changing
+// commands only when the current state allows them.
+func completeCommands() []string {
+	w, err := workflow.Load()
+
+	allowed := make(map[string]bool)
+	if err == nil {
+		for _, action := range state.NextValidActions(w.State) {
+			name, _, _ := strings.Cut(action, " ")
+			allowed[name] = true
+		}
+	}
+
+	var names []string
+	for _, c := range Commands {
+		switch {
+		case c.Hidden:
+			continue
+		case err != nil && !workflowlessCommands[c.Name]:
+			continue
+		case err == nil && c.Name == "start":
+			continue
+		case err == nil && transitionCommands[c.Name] && !allowed[c.Name]:
+			continue
+		}
+		names = append(names, c.Name)
+	}
+	return append(names, "help")
+}
+
+// completeArgs completes a flag, a flag's value or a positional argument
+// for c, given the arguments before the one being completed.
+func completeArgs(c *Command, prev []string, cur string) []string {
+	// A value flag written --flag value
+	if len(prev) > 0 {
+		last := prev[len(prev)-1]
+		if name, ok := strings.CutPrefix(last, "--"); ok && !strings.Contains(name, "=") {
+			if f := c.lookupFlag(name, false); f != nil && f.Value != "" && (!f.Optional || c.Args == "") {
+				return flagValues(f)
+			}
+		}
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		if name, _, ok := strings.Cut(strings.TrimLeft(cur, "-"), "="); ok {
+			f := c.lookupFlag(name, false)
+			if f == nil {
+				return nil
+			}
+			var values []string
+			for _, v := range flagValues(f) {
+				values = append(values, "--"+f.Name+"="+v)
+			}
+			return values
+		}
+
+		var flags []string
+		for _, f := range c.Flags {
+			if f.Value == "" || f.Optional {
+				flags = append(flags, "--"+f.Name)
+			}
+			if f.Value != "" {
+				flags = append(flags, "--"+f.Name+"=")
+			}
+		}
+		return append(flags, "--help")
+	}
+
+	return positionalValues(c, positionals(c, prev))
+}
+
+// positionals returns the positional arguments among args.
+func positionals(c *Command, args []string) []string {
+	p, err := c.Parse(args)
+	if err != nil {
+		return nil
+	}
+	return p.Args
+}
+
+// flagValues lists the known values for a flag.
+func flagValues(f *Flag) []string {
+	switch f.Name {
+	case "review":
+		registry, err := reviewer.LoadRegistry()
+		if err != nil {
+			return nil
+		}
+		return registry.Names()
+	case "generate":
+		return shaper.Names()
+	case "type":
+		return workflow.NoteTypes
+	case "issues":
+		return issues.Providers
+	default:
+		return nil
+	}
+}
+
+// positionalValues lists candidates for the next positional argument of c.
+func positionalValues(c *Command, args []string) []string {
+	switch c.Name {
+	case "completion":
+		if len(args) == 0 {
+			return completionShells
+		}
+	case "prompts":
+		if len(args) == 0 {
+			return []string{"show", "eject"}
+		}
+		if args[0] == "eject" || (args[0] == "show" && len(args) == 1) {
+			return prompts.Names
+		}
+	case "intent":
+		if len(args) == 0 {
+			return []string{"edit", "set"}
+		}
+	case "config":
+		if len(args) == 0 {
+			return []string{"get", "set", "list"}
+		}
+		if len(args) == 1 && (args[0] == "get" || args[0] == "set") {
+			cfg, err := config.Load()
+			if err != nil {
+				return nil
+			}
+			return cfg.Keys()
+		}
+	case "resolve":
+		if len(args) == 0 {
+			w, err := workflow.Load()
+			if err != nil {
+				return nil
+			}
+			var ids []string
+			for _, concern := range w.OpenConcerns("") {
+				ids = append(ids, concern.ID)
+			}
+			return ids
+		}
+	}
+	return nil
+}
+
+const bashCompletion = `# bash completion for craft
+# Add to ~/.bashrc: source <(craft completion bash)
+
+_craft() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -ra words <<< "$line"
+    if [[ "$line" == *" " ]]; then
+        words+=("")
+    fi
+    local cur="${words[${#words[@]}-1]}"
+
+    local IFS=$'\n'
+    COMPREPLY=($(craft __complete "${words[@]:1}" 2>/dev/null))
+
+    # Bash splits --flag=value at "=", so complete only the value part
+    if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+        COMPREPLY=("${COMPREPLY[@]#*=}")
+    fi
+    if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == *= ]] && type compopt &>/dev/null; then
+        compopt -o nospace
+    fi
+}
+
+complete -F _craft craft
+`
+
+const zshCompletion = `#compdef craft
+# zsh completion for craft
+# Add to ~/.zshrc: source <(craft completion zsh)
+
+_craft() {
+    local -a candidates
+    candidates=("${(@f)$(craft __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+
+    local c
+    for c in $candidates; do
+        if [[ "$c" == *= ]]; then
+            compadd -S '' -- "$c"
+        elif [[ -n "$c" ]]; then
+            compadd -- "$c"
+        fi
+    done
+}
+
+compdef _craft craft
+`
+
+const fishCompletion = `# fish completion for craft
+# Add to ~/.config/fish/config.fish: craft completion fish | source
+
+function __craft_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    craft __complete $tokens[2..-1] 2>/dev/null
+end
+
+complete -c craft -f -a '(__craft_complete)'
+`
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"craft/internal/config"
+)
+
+// maskedSecret replaces secret values in output.
+const maskedSecret = "********"
+
+var configCommand = &Command{
+	Name:    "config",
+	Args:    "<cmd> [key] [value]",
+	Summary: "Show or change user and project settings",
+	Forms: []Form{
+		{Args: "get <key>", Summary: "Print a setting's effective value"},
+		{Args: "set <key> <v>", Summary: "Set a value in the project config (--user for yours)"},
+		{Args: "list", Summary: "List every setting after layering"},
+	},
+	Flags: []Flag{
+		{Name: "show-origin", Usage: "Show the file or variable each value came from"},
+		{Name: "user", Usage: "With set, write the user config instead of the project's"},
+		{Name: "reveal", Usage: "With get, print a secret instead of masking it"},
+	},
+	Run: runConfig,
+}
+
+// Config inspects and changes the layered configuration.
+func Config(args []string) int {
+	return configCommand.Execute(args)
+}
+
+func runConfig(p *Parsed) int {
+	if len(p.Args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: Usage: craft config get <key> | set <key> <value> | list")
+		return 1
+	}
+
+	switch p.Args[0] {
+	case "get":
+		return configGet(p)
+	case "set":
+		return configSet(p)
+	case "list":
+		return configList(p)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown config subcommand '%s'\n", p.Args[0])
+		fmt.Fprintln(os.Stderr, "Usage: craft config get <key> | set <key> <value> | list")
+		return 1
+	}
+}
+
+func configGet(p *Parsed) int {
+	if len(p.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "Error: Key required. Usage: craft config get <key>")
+		return 1
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	v, ok := cfg.Lookup(p.Args[1])
+	if !ok {
+		return 1
+	}
+
+	value := v.String()
+	if config.IsSecret(p.Args[1]) && !p.Bool("reveal") {
+		value = maskedSecret
+	}
+	if p.Bool("show-origin") {
+		fmt.Printf("%s\t", v.Origin)
+	}
+	fmt.Println(value)
+	return 0
+}
+
+func configSet(p *Parsed) int {
+	if len(p.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "Error: Key and value required. Usage: craft config set [--user] <key> <value>")
+		return 1
+	}
+	key, value := p.Args[1], p.Args[2]
+
+	path := config.ProjectPath()
+	if p.Bool("user") {
+		if config.IsProjectOnly(key) {
+			fmt.Fprintf(os.Stderr, "Error: %s can only be set in the project config, since it applies to everyone working on the project.\n", key)
+			return 1
+		}
+		path = config.UserPath()
+	} else if config.IsUserOnly(key) {
+		fmt.Fprintf(os.Stderr, "Error: %s can only be set in the user config, since the project config is meant to be committed. Use --user.\n", key)
+		return 1
+	}
+
+	if err := config.SetFile(path, key, value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Set %s in %s\n", key, path)
+	return 0
+}
+
+func configList(p *Parsed) int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	for _, key := range cfg.Keys() {
+		v, _ := cfg.Lookup(key)
+		value := v.String()
+		if config.IsSecret(key) {
+			value = maskedSecret
+		}
+		if p.Bool("show-origin") {
+			fmt.Printf("%s\t", v.Origin)
+		}
+		fmt.Printf("%s = %s\n", key, value)
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"craft/internal/bundle"
+	"craft/internal/config"
+	"craft/internal/issues"
+	"craft/internal/workflow"
+)
+
+var exportCommand = &Command{
+	Name:    "export",
+	Summary: "Write the workflow to a bundle, or its cards to an issue tracker",
+	Flags: []Flag{
+		{Name: "bundle", Value: "file", Usage: "Write the workflow, pitch, cards and reviews to a .tar.gz"},
+		{Name: "issues", Value: "provider", Usage: "Create or update an issue per card: github or webhook"},
+	},
+	Run: runExport,
+}
+
+// Export writes the workflow to a portable bundle, or pushes its cards to
+// an issue tracker.
+func Export(args []string) int {
+	return exportCommand.Execute(args)
+}
+
+func runExport(p *Parsed) int {
+	dest, provider := p.String("bundle"), p.String("issues")
+	if dest == "" && provider == "" {
+		fmt.Fprintln(os.Stderr, "Error: Nothing to export. Usage: craft export --bundle <file> | --issues <provider>")
+		return 1
+	}
+	if !workflow.Exists() {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	// Issues first, so a bundle written in the same run carries their URLs
+	if provider != "" {
+		if code := exportIssues(provider); code != 0 {
+			return code
+		}
+	}
+	if dest == "" {
+		return 0
+	}
+
+	m, err := bundle.Export(dest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Exported %s to %s\n", countFiles(len(m.Files)), dest)
+	fmt.Printf("Import on another machine with: craft import %s\n", dest)
+	return 0
+}
+
+// exportIssues creates or updates an issue for every card.
+func exportIssues(provider string) int {
+	cards, err := issues.LoadCards()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(cards) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: No cards to export. Run 'craft shape' to create them.")
+		return 1
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	p, err := issues.Get(provider, cfg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	results, err := issues.Push(p, cards)
+	for _, r := range results {
+		verb := "Updated"
+		if r.Created {
+			verb = "Created"
+		}
+		fmt.Printf("%s %s: %s\n", verb, filepath.Base(r.Card.Path), r.Card.URL)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if len(results) > 0 {
+			fmt.Fprintln(os.Stderr, "Issues created so far are recorded in their cards; run the export again to continue.")
+		}
+		return 1
+	}
+	return 0
+}
+
+// countFiles returns "1 file" or "n files".
+func countFiles(n int) string {
+	if n == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", n)
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// errHelp is returned by Parse when help was requested.
+var errHelp = errors.New("help requested")
+
+// flagRegex matches arguments written as a flag, -x or --name with an
+// optional =value. Others starting with "-", such as "-5% latency" or
+// "-- see above", are positional.
+var flagRegex = regexp.MustCompile(`^(?:--[A-Za-z][A-Za-z0-9-]*|-[A-Za-z])(?:=|$)`)
+
+// helpColumn is the width of the name column in help listings.
+const helpColumn = 17
+
+// Flag describes a command-line flag.
+type Flag struct {
+	Name     string // Long name without dashes, e.g. "skip-shaping"
+	Short    string // Optional one-letter alias, e.g. "f"
+	Value    string // Placeholder for the value, e.g. "usd"; empty for boolean flags
+	Optional bool   // The value may be omitted, as in --review or --review=ai
+	Usage    string
+}
+
+// Form is an extra usage line listed under a command, e.g. a subcommand.
+type Form struct {
+	Args    string
+	Summary string
+}
+
+// Command describes a craft subcommand. Help output is generated from it.
+type Command struct {
+	Name    string
+	Args    string // Positional arguments for usage lines; empty if none are accepted
+	Summary string
+	Forms   []Form
+	Flags   []Flag
+	Hidden  bool // Left out of help, e.g. for shell completion internals
+	Raw     bool // Pass every argument through as positional, unparsed
+	Run     func(p *Parsed) int
+}
+
+// Parsed holds the flags and positional arguments given to a command.
+type Parsed struct {
+	Command *Command
+	Args    []string // Positional arguments, in order
+	values  map[string]string
+	set     map[string]bool
+}
+
+// Bool returns true if the flag was given.
+func (p *Parsed) Bool(name string) bool {
+	return p.set[name]
+}
+
+// String returns the flag's value, or "" if it was not given or has no value.
+func (p *Parsed) String(name string) string {
+	return p.values[name]
+}
+
+// Execute parses args and runs the command. --help prints the command's
+// help; a usage error prints it to stderr and returns 1.
+func (c *Command) Execute(args []string) int {
+	p, err := c.Parse(args)
+	if errors.Is(err, errHelp) {
+		c.WriteHelp(os.Stdout)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Run 'craft %s --help' for usage.\n", c.Name)
+		return 1
+	}
+	return c.Run(p)
+}
+
+// Parse separates flags from positional arguments. Flags may be written
+// --flag=value or --flag value and may appear between positional
+// arguments; everything after "--" is positional, as is text that is not
+// written as a flag. Unknown flags are errors.
+func (c *Command) Parse(args []string) (*Parsed, error) {
+	p := &Parsed{Command: c, values: map[string]string{}, set: map[string]bool{}}
+	if c.Raw {
+		p.Args = args
+		return p, nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			p.Args = append(p.Args, args[i+1:]...)
+			break
+		}
+		if arg == "-h" || arg == "--help" {
+			return nil, errHelp
+		}
+		if !flagRegex.MatchString(arg) {
+			p.Args = append(p.Args, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := c.lookupFlag(name, !strings.HasPrefix(arg, "--"))
+		if f == nil {
+			return nil, fmt.Errorf("unknown flag %s (put -- before text that starts with -)", arg)
+		}
+
+		switch {
+		case f.Value == "" && hasValue:
+			return nil, fmt.Errorf("flag --%s does not take a value", f.Name)
+		case f.Value != "" && !hasValue:
+			// Take the next argument as the value unless that would
+			// swallow a positional argument or another flag
+			next := i+1 < len(args) && args[i+1] != "--" && !flagRegex.MatchString(args[i+1])
+			if next && (!f.Optional || c.Args == "") {
+				value, hasValue = args[i+1], true
+				i++
+			} else if !f.Optional {
+				return nil, fmt.Errorf("flag --%s requires a value", f.Name)
+			}
+		}
+
+		p.set[f.Name] = true
+		if hasValue {
+			p.values[f.Name] = value
+		}
+	}
+
+	if c.Args == "" && len(p.Args) > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", p.Args[0])
+	}
+	return p, nil
+}
+
+func (c *Command) lookupFlag(name string, short bool) *Flag {
+	for i := range c.Flags {
+		f := &c.Flags[i]
+		if (short && f.Short != "" && f.Short == name) || (!short && f.Name == name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// UsageLine returns e.g. "craft accept [flags] [note]".
+func (c *Command) UsageLine() string {
+	parts := []string{"craft", c.Name}
+	if len(c.Flags) > 0 {
+		parts = append(parts, "[flags]")
+	}
+	if c.Args != "" {
+		parts = append(parts, c.Args)
+	}
+	return strings.Join(parts, " ")
+}
+
+// WriteHelp writes the command's own help.
+func (c *Command) WriteHelp(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s\n\n", c.UsageLine())
+	fmt.Fprintln(w, c.Summary)
+
+	if len(c.Forms) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Usage forms:")
+		for _, form := range c.Forms {
+			writeHelpLine(w, c.Name+" "+form.Args, form.Summary)
+		}
+	}
+
+	if len(c.Flags) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Flags:")
+		c.WriteFlags(w)
+	}
+}
+
+// WriteFlags writes one line per flag.
+func (c *Command) WriteFlags(w io.Writer) {
+	for _, f := range c.Flags {
+		writeHelpLine(w, f.synopsis(), f.Usage)
+	}
+}
+
+// synopsis returns e.g. "--appetite=<d>", "--review[=<name>]" or "-f, --force".
+func (f Flag) synopsis() string {
+	s := "--" + f.Name
+	if f.Short != "" {
+		s = "-" + f.Short + ", " + s
+	}
+	switch {
+	case f.Value != "" && f.Optional:
+		s += "[=<" + f.Value + ">]"
+	case f.Value != "":
+		s += "=<" + f.Value + ">"
+	}
+	return s
+}
+
+// writeHelpLine writes an indented two-column help line. Names too long
+// for the column push the description onto the next line.
+func writeHelpLine(w io.Writer, name, summary string) {
+	if len(name) > helpColumn {
+		fmt.Fprintf(w, "  %s\n  %-*s  %s\n", name, helpColumn, "", summary)
+		return
+	}
+	fmt.Fprintf(w, "  %-*s  %s\n", helpColumn, name, summary)
+}
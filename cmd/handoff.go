@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"craft/internal/config"
+	"craft/internal/identity"
+	"craft/internal/state"
+	"craft/internal/undo"
+	"craft/internal/workflow"
+)
+
+var handoffCommand = &Command{
+	Name:    "handoff",
+	Args:    "<person> \"<context>\"",
+	Summary: "Make someone else the owner, with context for them",
+	Run:     runHandoff,
+}
+
+// Handoff changes the workflow's owner and records why in history.
+func Handoff(args []string) int {
+	return handoffCommand.Execute(args)
+}
+
+func runHandoff(p *Parsed) int {
+	if len(p.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "Error: Person and context required. Usage: craft handoff <person> \"<context>\"")
+		return 1
+	}
+	person := p.Args[0]
+	context := strings.Trim(strings.Join(p.Args[1:], " "), "\"'")
+
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	if w.State == state.Shipped {
+		fmt.Fprintln(os.Stderr, "Error: Workflow already shipped. Run `craft reset` to start new work.")
+		return 1
+	}
+
+	if err := w.Handoff(person, context); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := undo.Save("handoff"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := w.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Handed off to %s. State: %s\n", w.Owner, w.State)
+	return 0
+}
+
+// twoPersonNote enforces the two-person rule, if w was started with it or
+// the project config turns it on, before the current user approves or
+// ships. It returns the history note naming the accepter, or "" when the
+// rule is off, and false if the user may not proceed.
+func twoPersonNote(w *workflow.Workflow) (string, bool) {
+	rule := w.TwoPerson
+	if !rule {
+		on, err := projectTwoPerson()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return "", false
+		}
+		rule = on
+	}
+	if !rule {
+		return "", true
+	}
+
+	accepter, err := w.CheckTwoPerson(identity.Current())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return "", false
+	}
+	return "Two-person rule: accepted by " + accepter, true
+}
+
+// projectTwoPerson reports whether the project config requires the
+// two-person rule for every workflow.
+func projectTwoPerson() (bool, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	v, _ := cfg.Get(config.KeyTwoPerson)
+	return v == "true", nil
+}
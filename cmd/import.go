@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"craft/internal/bundle"
+	"craft/internal/undo"
+	"craft/internal/workflow"
+)
+
+var importCommand = &Command{
+	Name:    "import",
+	Args:    "<bundle>",
+	Summary: "Continue a workflow exported with 'craft export --bundle'",
+	Flags: []Flag{
+		{Name: "replace", Usage: "Archive the current workflow and import over it"},
+	},
+	Run: runImport,
+}
+
+// Import installs a workflow bundle after verifying its hashes. The
+// import is recorded in history.
+func Import(args []string) int {
+	return importCommand.Execute(args)
+}
+
+func runImport(p *Parsed) int {
+	if len(p.Args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: Bundle required. Usage: craft import <bundle>")
+		return 1
+	}
+	src := p.Args[0]
+
+	b, err := bundle.Read(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if workflow.Exists() {
+		if !p.Bool("replace") {
+			fmt.Fprintln(os.Stderr, "Error: Workflow already exists. Use --replace to archive it and import the bundle.")
+			return 1
+		}
+		// Keep a copy for `craft stats`, as reset does
+		if _, err := workflow.Archive(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if err := b.Install(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	note := "Imported from " + filepath.Base(src)
+	if b.Manifest.ExportedBy != "" {
+		note += ", exported by " + b.Manifest.ExportedBy
+	}
+	w.RecordTransition(note)
+	if err := w.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := workflow.Register(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Snapshots from the replaced workflow can't be undone into this one
+	if err := undo.Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Imported \"%s\" (%s). State: %s\n", w.Title(), countFiles(len(b.Manifest.Files)), w.State)
+	return 0
+}
@@ -6,11 +6,27 @@ import (
 	"path/filepath"
 
 	"craft/internal/templates"
+	"craft/internal/workflow"
 )
 
-// Init copies integration templates to the current project.
+var initCommand = &Command{
+	Name:    "init",
+	Summary: "Copy AI integration templates",
+	Flags: []Flag{
+		{Name: "claude", Usage: "Copy Claude Code templates (CLAUDE.md, .claude/commands/)"},
+		{Name: "cursor", Usage: "Copy Cursor rules (.cursorrules)"},
+		{Name: "all", Usage: "Copy all templates"},
+	},
+	Run: runInit,
+}
+
+// Init copies integration templates to the project root.
 func Init(args []string) int {
-	flags := parseInitFlags(args)
+	return initCommand.Execute(args)
+}
+
+func runInit(p *Parsed) int {
+	flags := initFlags{claude: p.Bool("claude"), cursor: p.Bool("cursor"), all: p.Bool("all")}
 
 	if !flags.claude && !flags.cursor && !flags.all {
 		fmt.Fprintln(os.Stderr, "Error: No template specified.")
@@ -63,21 +79,6 @@ type initFlags struct {
 	all    bool
 }
 
-func parseInitFlags(args []string) initFlags {
-	var flags initFlags
-	for _, arg := range args {
-		switch arg {
-		case "--claude":
-			flags.claude = true
-		case "--cursor":
-			flags.cursor = true
-		case "--all":
-			flags.all = true
-		}
-	}
-	return flags
-}
-
 func installClaudeTemplates() (created, skipped []string, hadError bool) {
 	// CLAUDE.md
 	claudeMD, err := templates.ClaudeTemplate()
@@ -86,10 +87,11 @@ func installClaudeTemplates() (created, skipped []string, hadError bool) {
 		hadError = true
 		return
 	}
-	if writeIfNotExists("CLAUDE.md", claudeMD) {
-		created = append(created, "CLAUDE.md")
+	mdPath := filepath.Join(workflow.Root(), "CLAUDE.md")
+	if writeIfNotExists(mdPath, claudeMD) {
+		created = append(created, mdPath)
 	} else {
-		skipped = append(skipped, "CLAUDE.md")
+		skipped = append(skipped, mdPath)
 	}
 
 	// .claude/commands/craft.md
@@ -99,7 +101,7 @@ func installClaudeTemplates() (created, skipped []string, hadError bool) {
 		hadError = true
 		return
 	}
-	cmdPath := filepath.Join(".claude", "commands", "craft.md")
+	cmdPath := filepath.Join(workflow.Root(), ".claude", "commands", "craft.md")
 	if writeIfNotExists(cmdPath, craftCmd) {
 		created = append(created, cmdPath)
 	} else {
@@ -116,10 +118,11 @@ func installCursorTemplates() (created, skipped []string, hadError bool) {
 		hadError = true
 		return
 	}
-	if writeIfNotExists(".cursorrules", cursorRules) {
-		created = append(created, ".cursorrules")
+	rulesPath := filepath.Join(workflow.Root(), ".cursorrules")
+	if writeIfNotExists(rulesPath, cursorRules) {
+		created = append(created, rulesPath)
 	} else {
-		skipped = append(skipped, ".cursorrules")
+		skipped = append(skipped, rulesPath)
 	}
 	return
 }
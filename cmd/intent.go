@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"craft/internal/display"
+	"craft/internal/identity"
+	"craft/internal/state"
+	"craft/internal/undo"
+	"craft/internal/workflow"
+)
+
+var intentCommand = &Command{
+	Name:    "intent",
+	Args:    "<cmd> [text]",
+	Summary: "Revise the intent while thinking",
+	Forms: []Form{
+		{Args: "edit", Summary: "Revise the intent in $EDITOR"},
+		{Args: "set \"<text>\"", Summary: "Replace the intent (- reads it from stdin)"},
+	},
+	Flags: []Flag{
+		{Name: "reason", Value: "why", Usage: "Why the intent changed (required)"},
+	},
+	Run: runIntent,
+}
+
+// Intent revises the intent while thinking, keeping the previous version.
+func Intent(args []string) int {
+	return intentCommand.Execute(args)
+}
+
+func runIntent(p *Parsed) int {
+	if len(p.Args) == 0 || (p.Args[0] != "edit" && p.Args[0] != "set") {
+		if len(p.Args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: Unknown intent subcommand '%s'\n", p.Args[0])
+		} else {
+			fmt.Fprintln(os.Stderr, "Error: Subcommand required.")
+		}
+		fmt.Fprintln(os.Stderr, "Usage: craft intent edit | set \"<text>\" --reason \"<why>\"")
+		return 1
+	}
+
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	// Checked before the editor opens
+	if w.State != state.Thinking {
+		fmt.Fprintf(os.Stderr, "Error: The intent is frozen once accepted. Current state: %s\n", w.State)
+		return 1
+	}
+	reason := strings.TrimSpace(p.String("reason"))
+	if reason == "" {
+		fmt.Fprintln(os.Stderr, "Error: Reason required. Usage: craft intent edit | set \"<text>\" --reason \"<why>\"")
+		return 1
+	}
+
+	var text string
+	switch {
+	case p.Args[0] == "edit":
+		text, err = editIntent(w.Intent)
+	case len(p.Args) == 2 && p.Args[1] == "-":
+		var data []byte
+		data, err = io.ReadAll(stdinReader)
+		text = string(data)
+	case len(p.Args) > 1:
+		text = strings.Trim(strings.Join(p.Args[1:], " "), "\"'")
+	default:
+		fmt.Fprintln(os.Stderr, "Error: Intent required. Usage: craft intent set \"<text>\" --reason \"<why>\"")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := w.ReviseIntent(text, reason); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := undo.Save("intent"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := w.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Intent revised (r%d). State: %s\n", len(w.Revisions), w.State)
+	return 0
+}
+
+// printRevisions lists the intent's revisions and shows how the latest
+// one changed it.
+func printRevisions(w *workflow.Workflow) {
+	if len(w.Revisions) == 0 {
+		return
+	}
+
+	fmt.Println("## Revisions")
+	for i, r := range w.Revisions {
+		by := ""
+		if r.Actor != "" {
+			by = " by " + identity.Name(r.Actor)
+		}
+		fmt.Printf("r%d %s%s: %s\n", i+1, r.At.Local().Format("2006-01-02 15:04"), by, r.Reason)
+	}
+	fmt.Println()
+
+	last := w.Revisions[len(w.Revisions)-1]
+	fmt.Printf("Changes in r%d:\n", len(w.Revisions))
+	for _, line := range display.LineDiff(last.Intent, w.Intent) {
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"craft/internal/state"
+	"craft/internal/undo"
+	"craft/internal/workflow"
+)
+
+// noteGroupTitles labels each note type when notes are grouped.
+var noteGroupTitles = map[string]string{
+	workflow.NoteDecision:   "Decisions",
+	workflow.NoteRisk:       "Risks",
+	workflow.NoteAssumption: "Assumptions",
+	workflow.NoteQuestion:   "Questions",
+	workflow.NoteGeneral:    "Other",
+}
+
+var noteCommand = &Command{
+	Name:    "note",
+	Args:    "\"<text>\"",
+	Summary: "Record a note in any state before shipping",
+	Flags: []Flag{
+		{Name: "type", Value: "type", Usage: "decision, risk, assumption or question"},
+	},
+	Run: runNote,
+}
+
+// Note records a typed note in any state before shipping.
+func Note(args []string) int {
+	return noteCommand.Execute(args)
+}
+
+func runNote(p *Parsed) int {
+	noteType := workflow.NoteGeneral
+	if p.Bool("type") {
+		noteType = p.String("type")
+	}
+
+	if !workflow.ValidNoteType(noteType) {
+		fmt.Fprintf(os.Stderr, "Error: Unknown note type '%s'. Use decision, risk, assumption or question.\n", noteType)
+		return 1
+	}
+
+	text := strings.TrimSpace(strings.Trim(strings.Join(p.Args, " "), "\"'"))
+	if text == "" {
+		fmt.Fprintln(os.Stderr, "Error: Note required. Usage: craft note [--type=decision|risk|assumption|question] \"<text>\"")
+		return 1
+	}
+
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	if w.State == state.Shipped {
+		fmt.Fprintln(os.Stderr, "Error: Workflow already shipped. Run `craft reset` to start new work.")
+		return 1
+	}
+
+	w.AddTypedNote(noteType, text)
+	if err := undo.Save("note"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := w.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Recorded %s. State: %s\n", noteType, w.State)
+	return 0
+}
+
+// printNotes lists notes, grouped by type once any typed note exists.
+// heading formats each group title, e.g. "### %s" or "%s:".
+func printNotes(notes []workflow.Note, heading string) {
+	if len(notes) == 0 {
+		fmt.Println("(none)")
+		return
+	}
+
+	typed := false
+	for _, n := range notes {
+		if n.Type != workflow.NoteGeneral {
+			typed = true
+			break
+		}
+	}
+	if !typed {
+		for _, n := range notes {
+			fmt.Println(formatNoteLine(n))
+		}
+		return
+	}
+
+	first := true
+	for _, t := range workflow.NoteTypes {
+		var group []workflow.Note
+		for _, n := range notes {
+			if n.Type == t {
+				group = append(group, n)
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Println()
+		}
+		first = false
+		fmt.Printf(heading+"\n", noteGroupTitles[t])
+		for _, n := range group {
+			fmt.Println(formatNoteLine(n))
+		}
+	}
+}
+
+// formatNoteLine renders a note with its author and phase, when known.
+func formatNoteLine(n workflow.Note) string {
+	var attribution []string
+	if n.Author != "" {
+		attribution = append(attribution, n.Author)
+	}
+	if n.Phase != "" {
+		attribution = append(attribution, string(n.Phase))
+	}
+	if len(attribution) == 0 {
+		return "- " + n.Text
+	}
+	return fmt.Sprintf("- %s (%s)", n.Text, strings.Join(attribution, ", "))
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"craft/internal/prompts"
+)
+
+var promptsCommand = &Command{
+	Name:    "prompts",
+	Args:    "[cmd]",
+	Summary: "List prompt templates and where they come from",
+	Forms: []Form{
+		{Args: "show <n>", Summary: "Print the default review, pitch or cards template"},
+		{Args: "eject [n]", Summary: "Copy default templates to .craft/prompts/ for editing"},
+	},
+	Run: runPrompts,
+}
+
+// Prompts lists, prints or ejects the prompt templates used for review and shaping.
+func Prompts(args []string) int {
+	return promptsCommand.Execute(args)
+}
+
+func runPrompts(p *Parsed) int {
+	args := p.Args
+	if len(args) == 0 {
+		return listPrompts()
+	}
+
+	switch args[0] {
+	case "show":
+		return showPrompt(args[1:])
+	case "eject":
+		return ejectPrompts(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Unknown prompts subcommand '%s'\n", args[0])
+		fmt.Fprintln(os.Stderr, "Usage: craft prompts [show <name> | eject [name...]]")
+		return 1
+	}
+}
+
+func listPrompts() int {
+	for _, name := range prompts.Names {
+		source := "default"
+		if prompts.Overridden(name) {
+			source = prompts.Path(name)
+		}
+		fmt.Printf("%-8s %s\n", name, source)
+	}
+	return 0
+}
+
+func showPrompt(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: Prompt name required. Usage: craft prompts show <name>")
+		return 1
+	}
+
+	content, err := prompts.Default(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(string(content))
+	return 0
+}
+
+func ejectPrompts(args []string) int {
+	names := args
+	if len(names) == 0 {
+		names = prompts.Names
+	}
+
+	for _, name := range names {
+		if !prompts.Valid(name) {
+			fmt.Fprintf(os.Stderr, "Error: unknown prompt: %s\n", name)
+			return 1
+		}
+	}
+
+	var hadError bool
+	for _, name := range names {
+		content, err := prompts.Default(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s template: %v\n", name, err)
+			hadError = true
+			continue
+		}
+		path := prompts.Path(name)
+		if writeIfNotExists(path, content) {
+			fmt.Printf("Created: %s\n", path)
+		} else {
+			fmt.Printf("Skipped: %s (already exists)\n", path)
+		}
+	}
+
+	if hadError {
+		return 1
+	}
+	return 0
+}
@@ -6,11 +6,24 @@ import (
 	"strings"
 
 	"craft/internal/state"
+	"craft/internal/undo"
 	"craft/internal/workflow"
 )
 
+var rejectCommand = &Command{
+	Name:    "reject",
+	Args:    "[note]",
+	Summary: "Record a concern, stay in thinking",
+	Run:     runReject,
+}
+
 // Reject records a concern and stays in thinking state.
 func Reject(args []string) int {
+	return rejectCommand.Execute(args)
+}
+
+func runReject(p *Parsed) int {
+	args := p.Args
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
@@ -32,8 +45,16 @@ func Reject(args []string) int {
 	}
 
 	// Record rejection in history (no state change, but note is recorded)
+	// and track it as a concern that must be answered before accepting
+	var concern workflow.Concern
 	if note != "" {
 		w.RecordTransition("Rejected: " + note)
+		concern = w.RaiseConcern(workflow.ConcernReject, note)
+	}
+
+	if err := undo.Save("reject"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
 	}
 
 	if err := w.Save(); err != nil {
@@ -41,6 +62,10 @@ func Reject(args []string) int {
 		return 1
 	}
 
-	fmt.Println("Concern recorded. State: thinking")
+	if concern.ID != "" {
+		fmt.Printf("Concern %s recorded. State: thinking\n", concern.ID)
+	} else {
+		fmt.Println("Concern recorded. State: thinking")
+	}
 	return 0
 }
@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 
+	"craft/internal/state"
+	"craft/internal/undo"
 	"craft/internal/workflow"
 )
 
@@ -14,16 +16,22 @@ import (
 // It can be replaced in tests to simulate user input.
 var stdinReader io.Reader = os.Stdin
 
+var resetCommand = &Command{
+	Name:    "reset",
+	Summary: "Abandon current workflow (archived for stats)",
+	Flags: []Flag{
+		{Name: "force", Short: "f", Usage: "Don't ask for confirmation"},
+	},
+	Run: runReset,
+}
+
 // Reset abandons the current workflow.
 func Reset(args []string) int {
-	// Check for --force flag
-	force := false
-	for _, arg := range args {
-		if arg == "--force" || arg == "-f" {
-			force = true
-			break
-		}
-	}
+	return resetCommand.Execute(args)
+}
+
+func runReset(p *Parsed) int {
+	force := p.Bool("force")
 
 	if !workflow.Exists() {
 		fmt.Println("No workflow to reset.")
@@ -41,19 +49,40 @@ func Reset(args []string) int {
 			}
 		}
 	} else if !force {
-		fmt.Printf("Abandon workflow \"%s\"? [y/N] ", w.Intent)
+		fmt.Printf("Abandon workflow \"%s\"? [y/N] ", w.Title())
 		if !confirm(stdinReader) {
 			fmt.Println("Cancelled.")
 			return 0
 		}
 	}
 
+	// Keep a copy for `craft stats`
+	if _, err := workflow.Archive(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := undo.Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Otherwise the next workflow would export this one's reviews
+	if err := workflow.ClearReviews(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
 	if err := workflow.Delete(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	fmt.Println("Workflow abandoned.")
+	if w != nil && w.State == state.Shipped {
+		fmt.Println("Workflow archived.")
+	} else {
+		fmt.Println("Workflow abandoned.")
+	}
 	return 0
 }
 
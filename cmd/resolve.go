@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"craft/internal/undo"
+	"craft/internal/workflow"
+)
+
+var resolveCommand = &Command{
+	Name:    "resolve",
+	Args:    "<id> \"<answer>\"",
+	Summary: "Answer an open concern",
+	Flags: []Flag{
+		{Name: "waive", Usage: "Waive the concern, giving a reason instead of an answer"},
+	},
+	Run: runResolve,
+}
+
+// Resolve answers an open concern, or waives it with a reason.
+func Resolve(args []string) int {
+	return resolveCommand.Execute(args)
+}
+
+func runResolve(p *Parsed) int {
+	waive := p.Bool("waive")
+	filteredArgs := p.Args
+
+	if len(filteredArgs) < 2 {
+		fmt.Fprintln(os.Stderr, "Error: Usage: craft resolve <id> \"<answer>\" or craft resolve <id> --waive \"<reason>\"")
+		return 1
+	}
+
+	w, err := workflow.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	id := filteredArgs[0]
+	answer := strings.Join(filteredArgs[1:], " ")
+	answer = strings.Trim(answer, "\"'")
+
+	if err := w.ResolveConcern(id, answer, waive); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := undo.Save("resolve"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := w.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	verb := "resolved"
+	if waive {
+		verb = "waived"
+	}
+	fmt.Printf("Concern %s %s.", strings.ToUpper(id), verb)
+	if open := len(w.OpenConcerns("")); open > 0 {
+		fmt.Printf(" %d still open.", open)
+	}
+	fmt.Println()
+	return 0
+}
+
+// printConcerns lists open concerns under a heading. Nothing is printed
+// when there are none.
+func printConcerns(concerns []workflow.Concern) {
+	if len(concerns) == 0 {
+		return
+	}
+	fmt.Println("Open concerns:")
+	for _, c := range concerns {
+		fmt.Printf("  %s [%s] %s\n", c.ID, c.Kind, c.Text)
+	}
+	fmt.Println()
+}
+
+// blockedByConcerns reports open concerns of kind that prevent advancing.
+func blockedByConcerns(w *workflow.Workflow, kind string) bool {
+	open := w.OpenConcerns(kind)
+	if len(open) == 0 {
+		return false
+	}
+
+	noun := "concerns"
+	if len(open) == 1 {
+		noun = "concern"
+	}
+	fmt.Fprintf(os.Stderr, "Error: %d open %s must be resolved first:\n", len(open), noun)
+	for _, c := range open {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", c.ID, c.Text)
+	}
+	fmt.Fprintln(os.Stderr, "Run `craft resolve <id> \"<answer>\"`, or `craft resolve <id> --waive \"<reason>\"`.")
+	return true
+}
@@ -6,11 +6,24 @@ import (
 	"strings"
 
 	"craft/internal/state"
+	"craft/internal/undo"
 	"craft/internal/workflow"
 )
 
+var reviseCommand = &Command{
+	Name:    "revise",
+	Args:    "\"note\"",
+	Summary: "Record a concern during shaping",
+	Run:     runRevise,
+}
+
 // Revise records a concern during shaping without advancing state.
 func Revise(args []string) int {
+	return reviseCommand.Execute(args)
+}
+
+func runRevise(p *Parsed) int {
+	args := p.Args
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
@@ -39,12 +52,19 @@ func Revise(args []string) int {
 
 	// Add note with [revise] prefix
 	w.AddNote("[revise] " + note)
+	w.RecordTransition("Revised: " + note)
+	concern := w.RaiseConcern(workflow.ConcernRevise, note)
+
+	if err := undo.Save("revise"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
 
 	if err := w.Save(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	fmt.Println("Concern recorded. State: shaping")
+	fmt.Printf("Concern %s recorded. State: shaping\n", concern.ID)
 	return 0
 }
@@ -4,14 +4,33 @@ import (
 	"fmt"
 	"os"
 
+	"craft/internal/repocontext"
 	"craft/internal/shaper"
 	"craft/internal/state"
 	"craft/internal/structure"
+	"craft/internal/undo"
+	"craft/internal/usage"
 	"craft/internal/workflow"
 )
 
+var shapeCommand = &Command{
+	Name:    "shape",
+	Summary: "Show shaping status",
+	Forms: []Form{
+		{Args: "--generate", Summary: "Generate pitch and cards using AI"},
+	},
+	Flags: []Flag{
+		{Name: "generate", Value: "name", Optional: true, Usage: "Generate with a specific shaper (ai, shape-cli, or plugin)"},
+	},
+	Run: runShape,
+}
+
 // Shape displays shaping status or generates structure with --generate flag.
 func Shape(args []string) int {
+	return shapeCommand.Execute(args)
+}
+
+func runShape(p *Parsed) int {
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
@@ -23,17 +42,8 @@ func Shape(args []string) int {
 		return 1
 	}
 
-	// Check for --generate flag
-	generate := false
-	for _, arg := range args {
-		if arg == "--generate" {
-			generate = true
-			break
-		}
-	}
-
-	if generate {
-		return generateStructure(w)
+	if p.Bool("generate") {
+		return generateStructure(w, p.String("generate"))
 	}
 
 	return showShapingStatus(w)
@@ -46,7 +56,7 @@ func showShapingStatus(w *workflow.Workflow) int {
 		return 1
 	}
 
-	fmt.Printf("Shaping: %s\n", w.Intent)
+	fmt.Printf("Shaping: %s\n", w.Title())
 
 	if pitch == "" && len(cards) == 0 {
 		fmt.Println("Structure: (none)")
@@ -65,23 +75,63 @@ func showShapingStatus(w *workflow.Workflow) int {
 	return 0
 }
 
-func generateStructure(w *workflow.Workflow) int {
-	s := shaper.GetBestShaper()
+func generateStructure(w *workflow.Workflow, shaperName string) int {
+	var s shaper.Shaper
+	if shaperName == "" {
+		s = shaper.GetBestShaper()
+		if s == nil || s.Name() == shaper.NameManual {
+			fmt.Println("No shaper available. Create .craft/pitch.md manually.")
+			return 0
+		}
+	} else {
+		var err error
+		s, err = shaper.GetShaper(shaperName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
 
-	if s == nil || s.Name() == shaper.NameManual {
-		fmt.Println("No shaper available. Create .craft/pitch.md manually.")
-		return 0
+	if s.Name() == shaper.NameAI {
+		if err := checkBudget(w); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	if ai, ok := s.(*shaper.AIShaper); ok {
+		// The pitch alone can spend what is left
+		ai.BeforeCards = func(spent []usage.Call) error {
+			return checkBudget(w, spent...)
+		}
 	}
 
 	fmt.Printf("Generating via %s...\n", s.Name())
 
 	req := shaper.ShapeRequest{
-		Intent: w.Intent,
-		Notes:  w.Notes,
+		Intent:  w.Intent,
+		Notes:   w.NoteTexts(),
+		Context: repocontext.Collect(workflow.Root(), repocontext.Budget()),
+	}
+
+	if err := undo.Save("shape --generate"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
 	}
 
 	result, err := s.Shape(req)
+	if recordErr := recordUsage(w, result.Usage); recordErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", recordErr)
+		return 1
+	}
+	warnUnpriced(w)
 	if err != nil {
+		undo.Discard()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	w.RecordTransition("Generated structure via " + s.Name())
+	if err := w.Save(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
@@ -5,11 +5,22 @@ import (
 	"os"
 
 	"craft/internal/state"
+	"craft/internal/undo"
 	"craft/internal/workflow"
 )
 
+var shipCommand = &Command{
+	Name:    "ship",
+	Summary: "Finalize the workflow",
+	Run:     runShip,
+}
+
 // Ship finalizes the workflow.
-func Ship(_ []string) int {
+func Ship(args []string) int {
+	return shipCommand.Execute(args)
+}
+
+func runShip(_ *Parsed) int {
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
@@ -28,7 +39,17 @@ func Ship(_ []string) int {
 		return 1
 	}
 
-	if err := w.Transition(state.Shipped); err != nil {
+	note, ok := twoPersonNote(w)
+	if !ok {
+		return 1
+	}
+
+	if err := w.TransitionWithNote(state.Shipped, note); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if err := undo.Save("ship"); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
@@ -40,6 +61,6 @@ func Ship(_ []string) int {
 
 	fmt.Println("Workflow complete. State: shipped")
 	fmt.Println()
-	fmt.Printf("Intent: %s\n", w.Intent)
+	printIntent(w.Intent)
 	return 0
 }
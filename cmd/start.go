@@ -2,39 +2,204 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"regexp"
 	"strings"
 
+	"craft/internal/undo"
 	"craft/internal/workflow"
 )
 
+// intentTemplate follows the intent in the editor for `craft start
+// --edit` and `craft intent edit`.
+const intentTemplate = `
+
+<!--
+Write the intent: what should change, and why. Context and success
+criteria can follow as paragraphs or lists. Comments are removed, and an
+empty intent cancels.
+-->
+`
+
+// htmlComment matches the comments removed from an edited intent.
+var htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+var startCommand = &Command{
+	Name:    "start",
+	Args:    "\"<intent>\"",
+	Summary: "Begin a new workflow with the given intent",
+	Forms: []Form{
+		{Args: "-", Summary: "Read the intent from stdin"},
+		{Args: "--file <path>", Summary: "Read the intent from a Markdown file"},
+		{Args: "--edit", Summary: "Write the intent in $EDITOR"},
+	},
+	Flags: []Flag{
+		{Name: "budget", Value: "usd", Usage: "Fail further AI calls once estimated spend reaches this"},
+		{Name: "two-person", Usage: "Require someone other than the accepter to approve and ship"},
+		{Name: "file", Value: "path", Usage: "Read the intent from a file"},
+		{Name: "edit", Usage: "Open $VISUAL or $EDITOR to write the intent"},
+	},
+	Run: runStart,
+}
+
 // Start begins a new workflow with the given intent.
 func Start(args []string) int {
-	if len(args) == 0 {
+	return startCommand.Execute(args)
+}
+
+func runStart(p *Parsed) int {
+	sources := len(p.Args)
+	if sources > 1 {
+		sources = 1
+	}
+	for _, flag := range []string{"file", "edit"} {
+		if p.Bool(flag) {
+			sources++
+		}
+	}
+	if sources == 0 {
 		fmt.Fprintln(os.Stderr, "Error: Intent required. Usage: craft start \"<intent>\"")
 		return 1
 	}
-
-	intent := strings.Join(args, " ")
-	intent = strings.Trim(intent, "\"'")
-	intent = strings.TrimSpace(intent)
-
-	if intent == "" {
-		fmt.Fprintln(os.Stderr, "Error: Intent cannot be empty. Usage: craft start \"<intent>\"")
+	if sources > 1 {
+		fmt.Fprintln(os.Stderr, "Error: Give the intent once: as arguments, -, --file or --edit.")
 		return 1
 	}
 
+	var budget float64
+	if p.Bool("budget") {
+		amount, err := parseBudget(p.String("budget"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		budget = amount
+	}
+
+	// Checked first so nobody writes an intent in the editor for nothing
 	if workflow.Exists() {
 		fmt.Fprintln(os.Stderr, "Error: Workflow already exists. Run 'craft reset' to abandon.")
 		return 1
 	}
 
+	text, err := readIntent(p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if strings.TrimSpace(text) == "" {
+		fmt.Fprintln(os.Stderr, "Error: Intent cannot be empty. Usage: craft start \"<intent>\"")
+		return 1
+	}
+	intent, err := workflow.CleanIntent(text)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Recorded in the workflow even when the project requires it, so the
+	// rule stays with the workflow if the config changes
+	twoPerson := p.Bool("two-person")
+	if !twoPerson {
+		on, err := projectTwoPerson()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		twoPerson = on
+	}
+
 	w := workflow.New(intent)
+	w.Budget = budget
+	w.TwoPerson = twoPerson
 	if err := w.Save(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
+	// Packages of a monorepo are listed by `craft status --all`
+	if err := workflow.Register(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Snapshots from a previous workflow can't be undone into this one
+	if err := undo.Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Nor do its reviews belong to this one
+	if err := workflow.ClearReviews(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
 	fmt.Println("Workflow started. State: thinking")
 	return 0
 }
+
+// readIntent returns the intent from the arguments, stdin ("-"), a file
+// or the editor.
+func readIntent(p *Parsed) (string, error) {
+	switch {
+	case p.Bool("file"):
+		data, err := os.ReadFile(p.String("file"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read intent: %w", err)
+		}
+		return string(data), nil
+	case p.Bool("edit"):
+		return editIntent("")
+	case len(p.Args) == 1 && p.Args[0] == "-":
+		data, err := io.ReadAll(stdinReader)
+		if err != nil {
+			return "", fmt.Errorf("failed to read intent from stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	// A quoted one-line intent from the shell
+	intent := strings.Join(p.Args, " ")
+	return strings.TrimSpace(strings.Trim(intent, "\"'")), nil
+}
+
+// editIntent opens the user's editor on the current intent, if any, and
+// the template, and returns what was written without comments.
+func editIntent(current string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "craft-intent-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create intent file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	_, err = f.WriteString(current + intentTemplate)
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write intent file: %w", err)
+	}
+
+	// Editors are often configured with arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read intent file: %w", err)
+	}
+	return htmlComment.ReplaceAllString(string(data), ""), nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"craft/internal/stats"
+)
+
+var statsCommand = &Command{
+	Name:    "stats",
+	Summary: "Show cycle times and outcomes across workflows",
+	Flags: []Flag{
+		{Name: "json", Usage: "Output as JSON"},
+		{Name: "openmetrics", Usage: "Output in OpenMetrics text format"},
+	},
+	Run: runStats,
+}
+
+// Stats reports cycle times and outcomes across past and current workflows.
+func Stats(args []string) int {
+	return statsCommand.Execute(args)
+}
+
+func runStats(p *Parsed) int {
+	format := "table"
+	switch {
+	case p.Bool("json") && p.Bool("openmetrics"):
+		fmt.Fprintln(os.Stderr, "Error: --json and --openmetrics cannot be combined")
+		return 1
+	case p.Bool("json"):
+		format = "json"
+	case p.Bool("openmetrics"):
+		format = "openmetrics"
+	}
+
+	records, source, err := stats.Load(time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	report := stats.Compute(records, source)
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	case "openmetrics":
+		report.WriteOpenMetrics(os.Stdout)
+	default:
+		if len(records) == 0 {
+			fmt.Println("No workflows found. Finished workflows are archived by `craft reset`.")
+			return 0
+		}
+		report.WriteTable(os.Stdout)
+	}
+	return 0
+}
@@ -2,15 +2,38 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"craft/internal/display"
+	"craft/internal/identity"
 	"craft/internal/state"
+	"craft/internal/usage"
 	"craft/internal/workflow"
 )
 
+var statusCommand = &Command{
+	Name:    "status",
+	Summary: "Show current state and valid actions",
+	Flags: []Flag{
+		{Name: "all", Usage: "Summarize every workflow in the repository"},
+	},
+	Run: runStatus,
+}
+
 // Status displays the current workflow state and valid actions.
-func Status(_ []string) int {
+func Status(args []string) int {
+	return statusCommand.Execute(args)
+}
+
+func runStatus(p *Parsed) int {
+	if p.Bool("all") {
+		return statusAll()
+	}
+
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Println("No workflow found. Run 'craft start' to begin.")
@@ -24,7 +47,22 @@ func Status(_ []string) int {
 	}
 
 	fmt.Printf("State: %s\n", w.State)
-	fmt.Printf("Intent: %s\n", w.Intent)
+	printIntent(w.Intent)
+	if w.IntentChangedSinceAccept() {
+		fmt.Printf("Warning: Intent changed since it was accepted (frozen as %s, now %s).\n", w.FrozenIntent, w.HashIntent())
+	}
+	if n := len(w.Revisions); n > 0 {
+		fmt.Printf("Revisions: %d (latest: %s)\n", n, w.Revisions[n-1].Reason)
+	}
+	if w.Owner != "" {
+		fmt.Printf("Owner: %s\n", w.Owner)
+	}
+	if w.TwoPerson {
+		fmt.Println("Two-person rule: on (approve and ship need someone other than the accepter)")
+	}
+	if contributors := w.Contributors(); len(contributors) > 0 {
+		fmt.Printf("Contributors: %s\n", strings.Join(contributors, ", "))
+	}
 
 	// Show started_at with relative time
 	if !w.StartedAt.IsZero() {
@@ -36,28 +74,111 @@ func Status(_ []string) int {
 	if len(w.History) > 0 {
 		fmt.Println("History:")
 		for _, h := range w.History {
-			timeStr := h.At.Local().Format("15:04")
+			line := h.At.Local().Format("15:04") + " " + h.State
 			if h.Note != "" {
-				fmt.Printf("  %s %s \"%s\"\n", timeStr, h.State, h.Note)
-			} else {
-				fmt.Printf("  %s %s\n", timeStr, h.State)
+				line += fmt.Sprintf(" \"%s\"", h.Note)
+			}
+			if h.Actor != "" {
+				line += " (" + identity.Name(h.Actor) + ")"
 			}
+			fmt.Printf("  %s\n", line)
 		}
 		fmt.Println()
 	}
 
 	fmt.Println("Notes:")
-	if len(w.Notes) == 0 {
-		fmt.Println("(none)")
-	} else {
-		for _, note := range w.Notes {
-			fmt.Printf("- %s\n", note)
+	printNotes(w.Notes, "%s:")
+	fmt.Println()
+
+	printConcerns(w.OpenConcerns(""))
+
+	now := time.Now()
+	if phases := w.Phases(now); len(phases) > 0 {
+		fmt.Printf("Phases: %s\n", formatPhases(phases))
+	}
+	if w.Appetite > 0 {
+		fmt.Printf("Appetite: %s\n", formatAppetite(w, now))
+		if w.State == state.Building && w.AppetiteExceeded(now) {
+			fmt.Println(appetiteWarning(w, now))
 		}
 	}
 	fmt.Println()
 
+	if len(w.Usage) > 0 || w.Budget > 0 {
+		prices, err := usage.Prices()
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		fmt.Printf("AI usage: %s\n", formatUsage(usage.Summarize(w.Usage, prices), w.Budget))
+		fmt.Println()
+	}
+
 	actions := state.NextValidActions(w.State)
 	fmt.Printf("Actions: %s\n", strings.Join(actions, ", "))
 
 	return 0
 }
+
+// statusAll prints one line per workflow in the repository: the root's,
+// those of registered packages, and any others found in the tree.
+func statusAll() int {
+	dirs, err := workflow.Workflows()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	now := time.Now()
+	repo := workflow.RepoRoot()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	found := 0
+	for _, dir := range dirs {
+		path := filepath.Join(repo, filepath.FromSlash(dir), workflow.DirName, workflow.WorkflowFile)
+		w, err := workflow.LoadFile(path)
+		if err != nil {
+			continue
+		}
+		if found == 0 {
+			fmt.Fprintln(tw, "Package\tState\tOwner\tIntent\tAge")
+		}
+		found++
+		age := roundDuration(now.Sub(w.StartedAt))
+		owner := identity.Name(w.Owner)
+		if owner == "" {
+			owner = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", dir, w.State, owner, truncate(w.Title(), 50), age)
+	}
+	tw.Flush()
+
+	if found == 0 {
+		fmt.Println("No workflows found. Run 'craft start' to begin.")
+	}
+	return 0
+}
+
+// printIntent prints a one-line intent after its label, and a longer one
+// indented below it.
+func printIntent(intent string) {
+	if !strings.Contains(intent, "\n") {
+		fmt.Printf("Intent: %s\n", intent)
+		return
+	}
+	fmt.Println("Intent:")
+	for _, line := range strings.Split(intent, "\n") {
+		if line == "" {
+			fmt.Println()
+		} else {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
+
+// truncate shortens s to at most n runes, marking the cut with "...".
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -5,36 +5,47 @@ import (
 	"os"
 	"strings"
 
+	"craft/internal/repocontext"
 	"craft/internal/reviewer"
 	"craft/internal/state"
 	"craft/internal/workflow"
 )
 
+var thinkCommand = &Command{
+	Name:    "think",
+	Summary: "Review the current workflow state",
+	Flags: []Flag{
+		{Name: "review", Value: "name", Optional: true, Usage: "Get questions from a reviewer (ai, council, heuristic, or plugin)"},
+	},
+	Run: runThink,
+}
+
 // Think displays the current workflow state for deliberation.
 func Think(args []string) int {
+	return thinkCommand.Execute(args)
+}
+
+func runThink(p *Parsed) int {
 	w, err := workflow.Load()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
 		return 1
 	}
 
-	// Parse --review flag
-	reviewFlag, reviewerName := parseReviewFlag(args)
+	reviewFlag, reviewerName := p.Bool("review"), p.String("review")
 
 	fmt.Println("# Intent")
 	fmt.Println(w.Intent)
 	fmt.Println()
 
+	printRevisions(w)
+
 	fmt.Println("## Notes")
-	if len(w.Notes) == 0 {
-		fmt.Println("(none)")
-	} else {
-		for _, note := range w.Notes {
-			fmt.Printf("- %s\n", note)
-		}
-	}
+	printNotes(w.Notes, "### %s")
 	fmt.Println()
 
+	printConcerns(w.OpenConcerns(""))
+
 	fmt.Printf("State: %s\n", w.State)
 	actions := state.NextValidActions(w.State)
 	fmt.Printf("Actions: %s\n", strings.Join(actions, ", "))
@@ -48,30 +59,20 @@ func Think(args []string) int {
 	return 0
 }
 
-// parseReviewFlag extracts --review or --review=X from args.
-// Returns (hasFlag, reviewerName). If reviewerName is empty, auto-detect.
-func parseReviewFlag(args []string) (bool, string) {
-	for _, arg := range args {
-		if arg == "--review" {
-			return true, ""
-		}
-		if strings.HasPrefix(arg, "--review=") {
-			return true, strings.TrimPrefix(arg, "--review=")
-		}
-	}
-	return false, ""
-}
-
 // runReview invokes the appropriate reviewer and displays output.
 func runReview(w *workflow.Workflow, reviewerName string) int {
-	var rev reviewer.Reviewer
-	var err error
+	registry, err := reviewer.LoadRegistry()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
 
+	var rev reviewer.Reviewer
 	if reviewerName == "" {
-		rev = reviewer.GetBestReviewer()
+		rev = registry.Best()
 		fmt.Printf("Reviewing with %s...\n\n", rev.Name())
 	} else {
-		rev, err = reviewer.GetReviewer(reviewerName)
+		rev, err = registry.Get(reviewerName)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 			return 1
@@ -79,17 +80,38 @@ func runReview(w *workflow.Workflow, reviewerName string) int {
 		fmt.Printf("Reviewing with %s...\n\n", rev.Name())
 	}
 
+	if rev.Name() == reviewer.NameAI {
+		if err := checkBudget(w); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+
 	req := reviewer.ReviewRequest{
-		Intent: w.Intent,
-		Notes:  w.Notes,
+		Intent:  w.Intent,
+		Notes:   w.NoteTexts(),
+		Context: repocontext.Collect(workflow.Root(), repocontext.Budget()),
 	}
 
 	resp, err := rev.Review(req)
+	if recordErr := recordUsage(w, resp.Usage); recordErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", recordErr)
+		return 1
+	}
+	warnUnpriced(w)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
 	}
 
 	fmt.Println(resp.Content)
+
+	// Kept so the review travels with `craft export --bundle`
+	path, err := workflow.SaveReview(rev.Name(), resp.Content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("\nReview saved to %s\n", path)
 	return 0
 }
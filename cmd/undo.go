@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"craft/internal/display"
+	"craft/internal/undo"
+	"craft/internal/workflow"
+)
+
+var undoCommand = &Command{
+	Name:    "undo",
+	Summary: "Revert the last transition, note or card change",
+	Run:     runUndo,
+}
+
+// Undo reverts the most recent change to the workflow, pitch or cards if it
+// is within the grace window. The undo is recorded in history.
+func Undo(args []string) int {
+	return undoCommand.Execute(args)
+}
+
+func runUndo(_ *Parsed) int {
+	if !workflow.Exists() {
+		fmt.Fprintln(os.Stderr, "Error: No workflow found. Run 'craft start' to begin.")
+		return 1
+	}
+
+	s, err := undo.Latest()
+	if errors.Is(err, undo.ErrNothingToUndo) {
+		fmt.Fprintln(os.Stderr, "Error: Nothing to undo.")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	grace, err := undo.Grace()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if time.Since(s.At) > grace {
+		fmt.Fprintf(os.Stderr, "Error: Last change (%s, %s) is outside the %s undo window.\n",
+			s.Action, display.RelativeTime(s.At), workflow.FormatDuration(grace))
+		return 1
+	}
+
+	w, err := undo.Restore(s)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Undid %s. State: %s\n", s.Action, w.State)
+	return 0
+}
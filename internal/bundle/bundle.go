@@ -0,0 +1,312 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"craft/internal/identity"
+	"craft/internal/structure"
+	"craft/internal/workflow"
+)
+
+const (
+	// ManifestFile lists every other file in a bundle with its hash.
+	ManifestFile = "manifest.json"
+
+	// Version is the bundle format written by Export.
+	Version = 1
+
+	// maxFileSize bounds each file read from a bundle.
+	maxFileSize = 10 << 20
+)
+
+// ErrNoWorkflow is returned when exporting without a workflow.
+var ErrNoWorkflow = errors.New("no workflow to export")
+
+// Manifest describes a bundle's contents.
+type Manifest struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	ExportedBy string    `json:"exported_by,omitempty"`
+	Intent     string    `json:"intent"`
+	State      string    `json:"state"`
+	Files      []File    `json:"files"`
+}
+
+// File is a bundled file, relative to the .craft directory with forward
+// slashes, and the SHA-256 of its content.
+type File struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// Bundle is a verified bundle read into memory.
+type Bundle struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// Files returns the paths of the workflow, pitch, cards and reviews,
+// relative to the .craft directory.
+func Files() ([]string, error) {
+	if !workflow.Exists() {
+		return nil, ErrNoWorkflow
+	}
+	files := []string{workflow.WorkflowFile}
+	if structure.HasPitch() {
+		files = append(files, structure.PitchFile)
+	}
+
+	cards, err := structure.ListCards()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cards: %w", err)
+	}
+	for _, card := range cards {
+		files = append(files, path.Join(structure.CardsDir, filepath.Base(card)))
+	}
+
+	reviews, err := workflow.ListReviews()
+	if err != nil {
+		return nil, err
+	}
+	for _, review := range reviews {
+		files = append(files, path.Join(workflow.ReviewsDir, filepath.Base(review)))
+	}
+	return files, nil
+}
+
+// Export writes the workflow, pitch, cards and reviews to a gzipped tar
+// file at dest, with a manifest of content hashes.
+func Export(dest string) (*Manifest, error) {
+	names, err := Files()
+	if err != nil {
+		return nil, err
+	}
+	w, err := workflow.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version:    Version,
+		ExportedAt: time.Now().UTC(),
+		ExportedBy: identity.Current(),
+		Intent:     w.Intent,
+		State:      string(w.State),
+	}
+	contents := make(map[string][]byte)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(workflow.Dir(), filepath.FromSlash(name)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		contents[name] = data
+		m.Files = append(m.Files, File{Path: name, SHA256: hash(data)})
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, ManifestFile, append(manifest, '\n'), m.ExportedAt); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := writeEntry(tw, name, contents[name], m.ExportedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if err := os.WriteFile(dest, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return m, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// Read opens the bundle at src and verifies every file against the
+// manifest. Files missing from the manifest, or listed but missing from
+// the bundle, are errors, as are paths outside the workflow's files.
+func Read(src string) (*Bundle, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", src, err)
+	}
+	defer gz.Close()
+
+	var manifest []byte
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle %s: %w", src, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("invalid bundle %s: %s is not a regular file", src, hdr.Name)
+		}
+		if hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("invalid bundle %s: %s is too large", src, hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle %s: %w", src, err)
+		}
+
+		if hdr.Name == ManifestFile {
+			manifest = data
+			continue
+		}
+		if !allowed(hdr.Name) {
+			return nil, fmt.Errorf("invalid bundle %s: unexpected file %s", src, hdr.Name)
+		}
+		if _, dup := files[hdr.Name]; dup {
+			return nil, fmt.Errorf("invalid bundle %s: %s appears twice", src, hdr.Name)
+		}
+		files[hdr.Name] = data
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("invalid bundle %s: no %s", src, ManifestFile)
+	}
+	b := &Bundle{files: files}
+	if err := json.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %s: %w", src, ManifestFile, err)
+	}
+	if b.Manifest.Version > Version {
+		return nil, fmt.Errorf("bundle %s is version %d; this craft reads up to version %d",
+			src, b.Manifest.Version, Version)
+	}
+	if err := b.verify(); err != nil {
+		return nil, fmt.Errorf("bundle %s failed verification: %w", src, err)
+	}
+	return b, nil
+}
+
+// verify checks the files against the manifest's hashes, and that the
+// workflow parses and matches its checksum.
+func (b *Bundle) verify() error {
+	listed := make(map[string]bool)
+	for _, f := range b.Manifest.Files {
+		data, ok := b.files[f.Path]
+		if !ok {
+			return fmt.Errorf("%s is listed in the manifest but missing", f.Path)
+		}
+		if hash(data) != f.SHA256 {
+			return fmt.Errorf("%s does not match its hash", f.Path)
+		}
+		listed[f.Path] = true
+	}
+
+	var extra []string
+	for name := range b.files {
+		if !listed[name] {
+			extra = append(extra, name)
+		}
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		return fmt.Errorf("%s not listed in the manifest", strings.Join(extra, ", "))
+	}
+
+	data, ok := b.files[workflow.WorkflowFile]
+	if !ok {
+		return fmt.Errorf("no %s", workflow.WorkflowFile)
+	}
+	w, err := workflow.Parse(data)
+	if err != nil {
+		return err
+	}
+	if err := w.ValidateChecksum(); err != nil {
+		return fmt.Errorf("%s: %w", workflow.WorkflowFile, err)
+	}
+	return nil
+}
+
+// Install writes the bundle's files into the .craft directory, replacing
+// the pitch, cards and reviews already there.
+func (b *Bundle) Install() error {
+	os.Remove(structure.PitchPath())
+	for _, dir := range []string{structure.CardsDirPath(), workflow.ReviewsPath()} {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", dir, err)
+		}
+	}
+
+	for _, f := range b.Manifest.Files {
+		dest := filepath.Join(workflow.Dir(), filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+		}
+		if err := os.WriteFile(dest, b.files[f.Path], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+	}
+	return nil
+}
+
+// allowed reports whether name is one of the files a bundle may carry:
+// the workflow, the pitch, or a Markdown file directly inside the cards or
+// reviews directory.
+func allowed(name string) bool {
+	if name == workflow.WorkflowFile || name == structure.PitchFile {
+		return true
+	}
+	dir, file := path.Split(name)
+	if file == "" || strings.HasPrefix(file, ".") || strings.ContainsAny(file, `\:`) || path.Ext(file) != ".md" {
+		return false
+	}
+	return dir == structure.CardsDir+"/" || dir == workflow.ReviewsDir+"/"
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,181 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"craft/internal/structure"
+	"craft/internal/workflow"
+)
+
+func setupTest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv(workflow.EnvDir, "")
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return dir
+}
+
+// writeBundle writes a bundle by hand so tests can tamper with it.
+func writeBundle(t *testing.T, path string, m Manifest, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifest, _ := json.Marshal(m)
+	writeEntry(tw, ManifestFile, manifest, m.ExportedAt)
+	for name, content := range files {
+		writeEntry(tw, name, []byte(content), m.ExportedAt)
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func TestExportRead(t *testing.T) {
+	dir := setupTest(t)
+
+	workflow.New("Add rate limiting").Save()
+	structure.EnsureStructureDir()
+	os.WriteFile(structure.PitchPath(), []byte("# Pitch\n"), 0644)
+	os.WriteFile(filepath.Join(structure.CardsDirPath(), "01-limiter.md"), []byte("# Limiter\n"), 0644)
+	workflow.SaveReview("Heuristic", "Who is rate limited?")
+
+	dest := filepath.Join(dir, "out.tar.gz")
+	m, err := Export(dest)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if m.Intent != "Add rate limiting" || len(m.Files) != 4 {
+		t.Errorf("Export() manifest = %+v, want the workflow, pitch, card and review", m)
+	}
+
+	b, err := Read(dest)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	// Install into a fresh project
+	t.Chdir(t.TempDir())
+	if err := b.Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	w, err := workflow.Load()
+	if err != nil || w.Intent != "Add rate limiting" {
+		t.Errorf("Load() after Install = %v, %v", w, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(structure.CardsDirPath(), "01-limiter.md")); string(data) != "# Limiter\n" {
+		t.Errorf("card = %q, want it copied", data)
+	}
+	if reviews, _ := workflow.ListReviews(); len(reviews) != 1 {
+		t.Errorf("ListReviews() = %v, want one", reviews)
+	}
+}
+
+func TestExportWithoutWorkflow(t *testing.T) {
+	dir := setupTest(t)
+
+	if _, err := Export(filepath.Join(dir, "out.tar.gz")); err != ErrNoWorkflow {
+		t.Errorf("Export() error = %v, want ErrNoWorkflow", err)
+	}
+}
+
+func TestReadRejectsTampering(t *testing.T) {
+	dir := setupTest(t)
+	workflowFile := workflow.New("Intent").Format()
+	editedFile := strings.Replace(workflowFile, "# Intent\nIntent\n", "# Intent\nSomething else\n", 1)
+
+	tests := []struct {
+		name  string
+		files []File
+		tar   map[string]string
+		want  string
+	}{
+		{
+			name:  "changed content",
+			files: []File{{Path: workflow.WorkflowFile, SHA256: hash([]byte(workflowFile))}},
+			tar:   map[string]string{workflow.WorkflowFile: workflowFile + "edited"},
+			want:  "does not match its hash",
+		},
+		{
+			name:  "workflow edited before export",
+			files: []File{{Path: workflow.WorkflowFile, SHA256: hash([]byte(editedFile))}},
+			tar:   map[string]string{workflow.WorkflowFile: editedFile},
+			want:  "checksum mismatch",
+		},
+		{
+			name: "missing file",
+			files: []File{
+				{Path: workflow.WorkflowFile, SHA256: hash([]byte(workflowFile))},
+				{Path: structure.PitchFile, SHA256: hash(nil)},
+			},
+			tar:  map[string]string{workflow.WorkflowFile: workflowFile},
+			want: "missing",
+		},
+		{
+			name:  "unlisted file",
+			files: []File{{Path: workflow.WorkflowFile, SHA256: hash([]byte(workflowFile))}},
+			tar:   map[string]string{workflow.WorkflowFile: workflowFile, "cards/extra.md": "x"},
+			want:  "not listed",
+		},
+		{
+			name:  "path outside .craft",
+			files: []File{{Path: "../evil.md", SHA256: hash([]byte("x"))}},
+			tar:   map[string]string{workflow.WorkflowFile: workflowFile, "../evil.md": "x"},
+			want:  "unexpected file",
+		},
+		{
+			name:  "no workflow",
+			files: []File{{Path: structure.PitchFile, SHA256: hash([]byte("x"))}},
+			tar:   map[string]string{structure.PitchFile: "x"},
+			want:  "no workflow.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "bundle.tar.gz")
+			writeBundle(t, path, Manifest{Version: Version, Files: tt.files}, tt.tar)
+
+			_, err := Read(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := map[string]bool{
+		"workflow.md":         true,
+		"pitch.md":            true,
+		"cards/01-a.md":       true,
+		"reviews/x-ai.md":     true,
+		"config.toml":         false,
+		"cards/sub/a.md":      false,
+		"cards/../x.md":       false,
+		"cards/.hidden.md":    false,
+		`cards/..\..\x.md`:    false,
+		"/etc/passwd":         false,
+		"undo/1/workflow.md":  false,
+		"reviews/review.json": false,
+	}
+	for name, want := range tests {
+		if got := allowed(name); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"craft/internal/repocontext"
+)
+
+const (
+	EnvRecord = "CRAFT_AI_RECORD"
+	EnvReplay = "CRAFT_AI_REPLAY"
+
+	formatVersion = 1
+)
+
+// treeSection matches the repository context's directory tree in a
+// prompt. It is left out of keys, so adding a file to the repository, the
+// cassette included, doesn't stop replay.
+var treeSection = regexp.MustCompile("(?s)### " + regexp.QuoteMeta(repocontext.TreeTitle) + "\n```\n.*?\n```\n*")
+
+// Doer is satisfied by http.Client and the HTTPClient seams of the
+// reviewer and shaper packages.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Key      string   `json:"key"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the normalized form of a recorded request.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+}
+
+// Cassette is the on-disk file format.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Wrap returns client wrapped for recording or replay according to
+// CRAFT_AI_REPLAY and CRAFT_AI_RECORD. Replay takes precedence. With
+// neither set, client is returned unchanged.
+func Wrap(client Doer) Doer {
+	if path := os.Getenv(EnvReplay); path != "" {
+		return &Replayer{Path: path}
+	}
+	if path := os.Getenv(EnvRecord); path != "" {
+		return &Recorder{Client: client, Path: path}
+	}
+	return client
+}
+
+// Replaying returns true if AI calls are served from a cassette.
+func Replaying() bool {
+	return os.Getenv(EnvReplay) != ""
+}
+
+// Load reads a cassette file. A missing file yields an empty cassette.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Cassette{Version: formatVersion}, nil
+		}
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	if c.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", c.Version, path)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette atomically.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder performs real requests and appends each exchange to a cassette.
+type Recorder struct {
+	Client Doer
+	Path   string
+
+	mu sync.Mutex
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	normalized, err := normalize(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := Load(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	c.Interactions = append(c.Interactions, Interaction{
+		Key:      Key(normalized),
+		Request:  normalized,
+		Response: Response{Status: resp.StatusCode, Body: string(body)},
+	})
+	if err := c.Save(r.Path); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Replayer answers requests from a cassette without touching the network.
+// Repeated identical requests are answered in recorded order; once those
+// run out, the last recording is reused.
+type Replayer struct {
+	Path string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	served   map[string]int
+}
+
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	normalized, err := normalize(req)
+	if err != nil {
+		return nil, err
+	}
+	key := Key(normalized)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cassette == nil {
+		c, err := Load(r.Path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.served = make(map[string]int)
+	}
+
+	// Keys are recomputed so cassettes stay valid when keying changes
+	var matches []Interaction
+	for _, in := range r.cassette.Interactions {
+		if Key(in.Request) == key {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no recorded response for request %s in %s (re-record with %s)", key[:12], r.Path, EnvRecord)
+	}
+
+	i := r.served[key]
+	if i >= len(matches) {
+		i = len(matches) - 1
+	}
+	r.served[key]++
+
+	rec := matches[i].Response
+	return &http.Response{
+		StatusCode: rec.Status,
+		Status:     fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(rec.Body)),
+		Request:    req,
+	}, nil
+}
+
+// Key returns the hash identifying a normalized request. The directory
+// tree is removed from strings in JSON bodies first.
+func Key(r Request) string {
+	body := r.Body
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err == nil {
+		if data, err := json.Marshal(stripTree(v)); err == nil {
+			body = string(data)
+		}
+	}
+	sum := sha256.Sum256([]byte(r.Method + " " + r.Path + "\n" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+// stripTree removes directory tree sections from every string in v.
+func stripTree(v any) any {
+	switch v := v.(type) {
+	case string:
+		return treeSection.ReplaceAllString(v, "")
+	case []any:
+		for i := range v {
+			v[i] = stripTree(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = stripTree(v[k])
+		}
+	}
+	return v
+}
+
+// normalize captures the parts of a request that identify it. Headers are
+// dropped (they carry credentials), the host is dropped so cassettes work
+// across base URLs, and JSON bodies are re-encoded with sorted keys. The
+// request body is restored so the request can still be sent.
+func normalize(req *http.Request) (Request, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return Request{}, fmt.Errorf("failed to read request: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Body:   canonicalJSON(body),
+	}, nil
+}
+
+// canonicalJSON re-encodes JSON with sorted keys; other content is returned as is.
+func canonicalJSON(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return string(body)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,152 @@
+package cassette
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"craft/internal/repocontext"
+)
+
+func post(t *testing.T, c Doer, url, body, auth string) string {
+	t.Helper()
+	req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+	req.Header.Set("Authorization", auth)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return string(data)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		io.WriteString(w, "reply to "+string(body))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "ai.json")
+
+	rec := &Recorder{Client: http.DefaultClient, Path: path}
+	got := post(t, rec, server.URL+"/v1/chat/completions", `{"model":"m","n":1}`, "Bearer secret")
+	if got != `reply to {"model":"m","n":1}` {
+		t.Errorf("recorded response = %q", got)
+	}
+	if calls != 1 {
+		t.Errorf("server calls = %d, want 1", calls)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret") {
+		t.Error("cassette should not contain request headers")
+	}
+
+	// Different host, key order and credentials still match
+	rep := &Replayer{Path: path}
+	got = post(t, rep, "http://localhost:1/v1/chat/completions", `{"n":1, "model":"m"}`, "Bearer other")
+	if got != `reply to {"model":"m","n":1}` {
+		t.Errorf("replayed response = %q", got)
+	}
+	if calls != 1 {
+		t.Errorf("server calls = %d after replay, want 1", calls)
+	}
+}
+
+func TestReplayInSameTree(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"choices":[{"message":{"content":"ok"}}]}`)
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("# Demo\n"), 0644)
+	body := func() string {
+		prompt := "Review this intent.\n\n" + repocontext.Collect(root, 4000)
+		data, _ := json.Marshal(map[string]any{"model": "m", "messages": []map[string]string{{"role": "user", "content": prompt}}})
+		return string(data)
+	}
+
+	// Recording into the repository adds the cassette to the tree
+	path := filepath.Join(root, "testdata", "c.json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	first := body()
+	post(t, &Recorder{Client: http.DefaultClient, Path: path}, server.URL+"/v1/chat/completions", first, "")
+
+	second := body()
+	if !strings.Contains(second, "c.json") || first == second {
+		t.Fatal("test setup: the cassette should appear in the context's tree")
+	}
+	if got := post(t, &Replayer{Path: path}, server.URL+"/v1/chat/completions", second, ""); !strings.Contains(got, "ok") {
+		t.Errorf("replayed response = %q", got)
+	}
+
+	// Other changes to the prompt still miss
+	changed := strings.Replace(second, "Review this intent", "Review that intent", 1)
+	req, _ := http.NewRequest("POST", server.URL+"/v1/chat/completions", strings.NewReader(changed))
+	if _, err := (&Replayer{Path: path}).Do(req); err == nil {
+		t.Error("Do() with a changed prompt should fail")
+	}
+}
+
+func TestReplayMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	rep := &Replayer{Path: path}
+
+	req, _ := http.NewRequest("POST", "http://example.com/v1/chat/completions", strings.NewReader(`{}`))
+	if _, err := rep.Do(req); err == nil {
+		t.Error("Do() should fail for unrecorded request")
+	}
+}
+
+func TestReplayInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ai.json")
+	req := Request{Method: "POST", Path: "/x", Body: `{"a":1}`}
+	c := &Cassette{Version: formatVersion, Interactions: []Interaction{
+		{Key: Key(req), Request: req, Response: Response{Status: 200, Body: "first"}},
+		{Key: Key(req), Request: req, Response: Response{Status: 200, Body: "second"}},
+	}}
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	rep := &Replayer{Path: path}
+	for _, want := range []string{"first", "second", "second"} {
+		if got := post(t, rep, "http://h/x", `{"a":1}`, ""); got != want {
+			t.Errorf("replay = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	os.Unsetenv(EnvRecord)
+	os.Unsetenv(EnvReplay)
+	if _, ok := Wrap(http.DefaultClient).(*http.Client); !ok {
+		t.Error("Wrap() without env should return client unchanged")
+	}
+
+	os.Setenv(EnvRecord, "rec.json")
+	defer os.Unsetenv(EnvRecord)
+	if _, ok := Wrap(http.DefaultClient).(*Recorder); !ok {
+		t.Error("Wrap() with CRAFT_AI_RECORD should return Recorder")
+	}
+
+	os.Setenv(EnvReplay, "rep.json")
+	defer os.Unsetenv(EnvReplay)
+	if _, ok := Wrap(http.DefaultClient).(*Replayer); !ok {
+		t.Error("Wrap() with CRAFT_AI_REPLAY should prefer Replayer")
+	}
+	if !Replaying() {
+		t.Error("Replaying() = false, want true")
+	}
+}
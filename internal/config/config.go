@@ -0,0 +1,422 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"craft/internal/workflow"
+)
+
+// ConfigFile is the configuration file name, both inside .craft/ for the
+// project and in the user's config directory.
+const ConfigFile = "config.toml"
+
+// UserDir is craft's directory under $XDG_CONFIG_HOME.
+const UserDir = "craft"
+
+// AI settings. The API key can also come from a command or a file.
+const (
+	KeyAIAPIKey     = "ai.api_key"
+	KeyAIAPIKeyCmd  = "ai.api_key_cmd"
+	KeyAIAPIKeyFile = "ai.api_key_file"
+	KeyAIModel      = "ai.model"
+	KeyAIBaseURL    = "ai.base_url"
+)
+
+// KeyTwoPerson requires every workflow's approve and ship to be done by
+// someone other than whoever accepted it.
+const KeyTwoPerson = "workflow.two_person"
+
+// KeyGitHubAPIURL is where `craft export --issues=github` sends
+// GITHUB_TOKEN, so a cloned repository mustn't be able to change it.
+const KeyGitHubAPIURL = "issues.github.api_url"
+
+// KeyWebhookURL is where `craft export --issues=webhook` sends
+// CRAFT_ISSUES_WEBHOOK_TOKEN, so it's user-only for the same reason.
+const KeyWebhookURL = "issues.webhook.url"
+
+// KeyPassEnv lists environment variables passed on to external tools.
+const KeyPassEnv = "exec.pass_env"
+
+// Plugin tables map names to executables run by craft.
+const (
+	KeyReviewerPlugins = "reviewers.plugins"
+	KeyShaperPlugins   = "shapers.plugins"
+)
+
+// EnvKeys maps environment variables to the settings they override.
+var EnvKeys = map[string]string{
+	"CRAFT_AI_API_KEY":  KeyAIAPIKey,
+	"CRAFT_AI_MODEL":    KeyAIModel,
+	"CRAFT_AI_BASE_URL": KeyAIBaseURL,
+}
+
+// secretKeys hold secrets, which are masked when listed.
+var secretKeys = map[string]bool{
+	KeyAIAPIKey: true,
+}
+
+// userKeys may only come from the user config or the environment, never
+// the project config, which is meant to be committed: secrets, and
+// settings that would let a cloned repository run commands, read files, or
+// send a secret somewhere else.
+var userKeys = map[string]bool{
+	KeyAIAPIKey:     true,
+	KeyAIAPIKeyCmd:  true,
+	KeyAIAPIKeyFile: true,
+	KeyAIBaseURL:    true,
+	KeyGitHubAPIURL: true,
+	KeyPassEnv:      true,
+	KeyWebhookURL:   true,
+}
+
+// userTables are tables whose every key is user-only.
+var userTables = []string{KeyReviewerPlugins, KeyShaperPlugins}
+
+// projectKeys are policies: they may only come from the project config, so
+// one person's user config can't opt out of them.
+var projectKeys = map[string]bool{
+	KeyTwoPerson: true,
+}
+
+// IsSecret returns true if key holds a secret.
+func IsSecret(key string) bool {
+	return secretKeys[key]
+}
+
+// IsUserOnly returns true if key is ignored in the project config.
+func IsUserOnly(key string) bool {
+	if userKeys[key] {
+		return true
+	}
+	for _, table := range userTables {
+		if key == table || strings.HasPrefix(key, table+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsProjectOnly returns true if key is ignored in the user config.
+func IsProjectOnly(key string) bool {
+	return projectKeys[key]
+}
+
+// Config holds flattened settings keyed by dotted path, e.g. "reviewers.priority".
+type Config struct {
+	values map[string]Value
+}
+
+// Value is a single setting: either a scalar or a list of strings.
+type Value struct {
+	Scalar string
+	List   []string
+	IsList bool
+	Origin string // File path, or "env:NAME" for an environment variable
+}
+
+// String renders the value in TOML syntax.
+func (v Value) String() string {
+	if !v.IsList {
+		return v.Scalar
+	}
+	quoted := make([]string, len(v.List))
+	for i, s := range v.List {
+		quoted[i] = strconv.Quote(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// ProjectPath returns the path to the project configuration file.
+func ProjectPath() string {
+	return filepath.Join(workflow.Dir(), ConfigFile)
+}
+
+// UserPath returns the path to the user configuration file:
+// $XDG_CONFIG_HOME/craft/config.toml, or ~/.config/craft/config.toml.
+func UserPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, UserDir, ConfigFile)
+}
+
+// Load reads the layered configuration: the user config, then the project
+// config, then environment variables, each overriding the one before.
+// Missing files are skipped. User-only keys in the project config, and
+// project-only keys in the user config, are ignored.
+func Load() (*Config, error) {
+	c := &Config{values: map[string]Value{}}
+
+	if path := UserPath(); path != "" {
+		user, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range user.values {
+			if !IsProjectOnly(k) {
+				c.values[k] = v
+			}
+		}
+	}
+
+	project, err := LoadFile(ProjectPath())
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range project.values {
+		if !IsUserOnly(k) {
+			c.values[k] = v
+		}
+	}
+
+	for env, key := range EnvKeys {
+		if v := os.Getenv(env); v != "" {
+			c.values[key] = Value{Scalar: v, Origin: "env:" + env}
+		}
+	}
+	return c, nil
+}
+
+// LoadFile reads a configuration file. A missing file yields an empty config.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{values: map[string]Value{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for k, v := range c.values {
+		v.Origin = path
+		c.values[k] = v
+	}
+	return c, nil
+}
+
+// Parse reads the TOML subset craft uses: [tables], and keys holding
+// strings, numbers, booleans or arrays of strings. Comments start with #.
+func Parse(data []byte) (*Config, error) {
+	c := &Config{values: map[string]Value{}}
+	table := ""
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: malformed table header", i+1)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if table == "" {
+				return nil, fmt.Errorf("line %d: empty table name", i+1)
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+		key = unquoteKey(strings.TrimSpace(key))
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", i+1)
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+
+		v, err := parseValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		c.values[key] = v
+	}
+
+	return c, nil
+}
+
+// Get returns the scalar value for key.
+func (c *Config) Get(key string) (string, bool) {
+	v, ok := c.values[key]
+	if !ok || v.IsList {
+		return "", false
+	}
+	return v.Scalar, true
+}
+
+// List returns the list value for key. A scalar is returned as a one-element list.
+func (c *Config) List(key string) ([]string, bool) {
+	v, ok := c.values[key]
+	if !ok {
+		return nil, false
+	}
+	if !v.IsList {
+		return []string{v.Scalar}, true
+	}
+	return v.List, true
+}
+
+// Table returns the scalar keys directly under prefix, without the prefix.
+func (c *Config) Table(prefix string) map[string]string {
+	out := make(map[string]string)
+	for k, v := range c.values {
+		rest, ok := strings.CutPrefix(k, prefix+".")
+		if !ok || strings.Contains(rest, ".") || v.IsList {
+			continue
+		}
+		out[rest] = v.Scalar
+	}
+	return out
+}
+
+// Lookup returns the value for key, with its origin.
+func (c *Config) Lookup(key string) (Value, bool) {
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// Keys returns every key, sorted.
+func (c *Config) Keys() []string {
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parseValue(raw string) (Value, error) {
+	switch {
+	case raw == "":
+		return Value{}, fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return Value{}, fmt.Errorf("unterminated array")
+		}
+		items, err := splitArray(raw[1 : len(raw)-1])
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{List: items, IsList: true}, nil
+	case strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"):
+		s, err := unquoteString(raw)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Scalar: s}, nil
+	default:
+		// Bare numbers and booleans are kept as written
+		return Value{Scalar: raw}, nil
+	}
+}
+
+func splitArray(inner string) ([]string, error) {
+	var items []string
+	for {
+		inner = strings.TrimSpace(inner)
+		if inner == "" {
+			return items, nil
+		}
+
+		var item string
+		if inner[0] == '"' || inner[0] == '\'' {
+			end := closingQuote(inner)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in array")
+			}
+			s, err := unquoteString(inner[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			item, inner = s, inner[end+1:]
+		} else {
+			next := strings.IndexByte(inner, ',')
+			if next < 0 {
+				next = len(inner)
+			}
+			item, inner = strings.TrimSpace(inner[:next]), inner[next:]
+		}
+		items = append(items, item)
+
+		inner = strings.TrimSpace(inner)
+		if inner != "" {
+			if inner[0] != ',' {
+				return nil, fmt.Errorf("expected comma in array")
+			}
+			inner = inner[1:]
+		}
+	}
+}
+
+// closingQuote returns the index of the quote ending the string that starts s.
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		if q == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == q {
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteString(s string) (string, error) {
+	if s[0] == '\'' {
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : len(s)-1], nil
+	}
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return v, nil
+}
+
+func unquoteKey(k string) string {
+	if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') && k[len(k)-1] == k[0] {
+		return k[1 : len(k)-1]
+	}
+	return k
+}
+
+// stripComment removes a trailing # comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0 && c == '\\' && quote == '"':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
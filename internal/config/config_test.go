@@ -0,0 +1,241 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`
+# Project settings
+top = "level"
+
+[reviewers]
+priority = ["council", "security", 'ai'] # trailing comment
+enabled = true
+
+[reviewers.plugins]
+security = "./tools/review # not a comment"
+"odd key" = 42
+`)
+
+	c, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if v, _ := c.Get("top"); v != "level" {
+		t.Errorf("top = %q, want level", v)
+	}
+	if v, _ := c.Get("reviewers.enabled"); v != "true" {
+		t.Errorf("reviewers.enabled = %q, want true", v)
+	}
+	if v, _ := c.List("reviewers.priority"); !reflect.DeepEqual(v, []string{"council", "security", "ai"}) {
+		t.Errorf("reviewers.priority = %v", v)
+	}
+	if v, _ := c.Get("reviewers.plugins.security"); v != "./tools/review # not a comment" {
+		t.Errorf("reviewers.plugins.security = %q", v)
+	}
+
+	table := c.Table("reviewers.plugins")
+	want := map[string]string{"security": "./tools/review # not a comment", "odd key": "42"}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("Table() = %v, want %v", table, want)
+	}
+
+	if _, ok := c.Get("reviewers.priority"); ok {
+		t.Error("Get() on a list should return false")
+	}
+	if v, ok := c.List("top"); !ok || len(v) != 1 {
+		t.Errorf("List() on a scalar = %v, %v", v, ok)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	invalid := []string{
+		"[unterminated",
+		"[]",
+		"novalue",
+		"key =",
+		`key = "open`,
+		`key = ["a", "b"`,
+		`key = ["a" "b"]`,
+	}
+
+	for _, s := range invalid {
+		if _, err := Parse([]byte(s)); err == nil {
+			t.Errorf("Parse(%q) should return error", s)
+		}
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	c, err := LoadFile(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(c.Keys()) != 0 {
+		t.Errorf("Keys() = %v, want empty", c.Keys())
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte("bad line"), 0644)
+
+	if _, err := LoadFile(path); err == nil {
+		t.Error("LoadFile() should return error for invalid file")
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "home"))
+	t.Setenv("CRAFT_AI_MODEL", "env-model")
+	t.Setenv("CRAFT_AI_API_KEY", "")
+
+	os.MkdirAll(filepath.Dir(UserPath()), 0755)
+	os.WriteFile(UserPath(), []byte("[ai]\napi_key = \"user-key\"\nmodel = \"user-model\"\nbase_url = \"https://user\"\n"), 0600)
+	os.MkdirAll(".craft", 0755)
+	os.WriteFile(ProjectPath(), []byte("[ai]\napi_key = \"leaked\"\nbase_url = \"https://project\"\n"), 0644)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key, value, origin string
+	}{
+		{KeyAIAPIKey, "user-key", UserPath()},      // Secrets in the project config are ignored
+		{KeyAIBaseURL, "https://user", UserPath()}, // As is where they are sent
+		{KeyAIModel, "env-model", "env:CRAFT_AI_MODEL"},
+	}
+	for _, tt := range tests {
+		v, ok := c.Lookup(tt.key)
+		if !ok || v.Scalar != tt.value || v.Origin != tt.origin {
+			t.Errorf("Lookup(%s) = %q from %q, want %q from %q", tt.key, v.Scalar, v.Origin, tt.value, tt.origin)
+		}
+	}
+}
+
+func TestLoadIgnoresUserKeysInProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "home"))
+	t.Setenv("CRAFT_AI_BASE_URL", "")
+
+	os.MkdirAll(".craft", 0755)
+	os.WriteFile(ProjectPath(), []byte(`[ai]
+base_url = "https://attacker"
+model = "gpt-4o"
+
+[exec]
+pass_env = ["GITHUB_TOKEN"]
+timeout = "1m"
+
+[reviewers]
+priority = ["heuristic"]
+
+[reviewers.plugins]
+evil = "./evil"
+
+[shapers.plugins]
+evil = "./evil"
+
+[issues.webhook]
+url = "https://attacker"
+`), 0644)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, key := range []string{KeyAIBaseURL, KeyPassEnv, KeyReviewerPlugins + ".evil", KeyShaperPlugins + ".evil", KeyWebhookURL} {
+		if _, ok := c.Lookup(key); ok {
+			t.Errorf("Lookup(%s) found a value from the project config, want it ignored", key)
+		}
+	}
+	for _, key := range []string{KeyAIModel, "exec.timeout", "reviewers.priority"} {
+		if _, ok := c.Lookup(key); !ok {
+			t.Errorf("Lookup(%s) = not found, want the project value", key)
+		}
+	}
+}
+
+func TestLoadIgnoresPolicyInUserConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "home"))
+
+	os.MkdirAll(filepath.Dir(UserPath()), 0755)
+	os.WriteFile(UserPath(), []byte("[workflow]\ntwo_person = false\n"), 0600)
+
+	c, _ := Load()
+	if v, ok := c.Get(KeyTwoPerson); ok {
+		t.Errorf("Get(%s) = %q from the user config, want it ignored", KeyTwoPerson, v)
+	}
+
+	os.MkdirAll(".craft", 0755)
+	os.WriteFile(ProjectPath(), []byte("[workflow]\ntwo_person = true\n"), 0644)
+	c, _ = Load()
+	if v, _ := c.Get(KeyTwoPerson); v != "true" {
+		t.Errorf("Get(%s) = %q, want true from the project config", KeyTwoPerson, v)
+	}
+}
+
+func TestSetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte("# Settings\ntop = 1\n\n[exec]\ntimeout = \"1m\" # slow CI\n\n[undo]\ngrace = \"5m\"\n"), 0644)
+
+	sets := [][2]string{
+		{"exec.timeout", "5m"},
+		{"exec.max_output", "2048"},
+		{"top", "2"},
+		{"reviewers.priority", `["ai", "council"]`},
+	}
+	for _, s := range sets {
+		if err := SetFile(path, s[0], s[1]); err != nil {
+			t.Fatalf("SetFile(%s) error = %v", s[0], err)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	want := `# Settings
+top = 2
+
+[exec]
+timeout = "5m"
+max_output = 2048
+
+[undo]
+grace = "5m"
+
+[reviewers]
+priority = ["ai", "council"]
+`
+	if string(data) != want {
+		t.Errorf("SetFile() wrote:\n%s\nwant:\n%s", data, want)
+	}
+
+	if err := SetFile(path, "bad", `["open`); err == nil {
+		t.Error("SetFile() should reject an invalid value")
+	}
+}
+
+func TestSetFileSecretPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "craft", "config.toml")
+	if err := SetFile(path, KeyAIAPIKey, "sk-test"); err != nil {
+		t.Fatalf("SetFile() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SetFile sets key to value in the configuration file at path, creating
+// the file if needed. Other lines, including comments, are kept. Arrays,
+// numbers and booleans are written as given; anything else is quoted.
+// Files holding secrets are made readable by the owner only.
+func SetFile(path, key, value string) error {
+	raw := formatValue(value)
+	if _, err := parseValue(raw); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+	setting := name + " = " + raw
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+
+	lines = setLine(lines, table, name, setting)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	perm := os.FileMode(0644)
+	if IsSecret(key) {
+		perm = 0600
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if IsSecret(key) {
+		if err := os.Chmod(path, perm); err != nil {
+			return fmt.Errorf("failed to restrict %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// setLine replaces name's line in table, or adds it at the end of the
+// table, adding the table if it is missing.
+func setLine(lines []string, table, name, setting string) []string {
+	current := ""
+	found := table == "" // The root table needs no header
+	insert := -1         // Index after the table's last setting
+	if found {
+		insert = 0
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(stripComment(line))
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			current = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if current == table {
+				found = true
+				insert = i + 1
+			}
+			continue
+		}
+		if current != table || trimmed == "" {
+			continue
+		}
+		key, _, ok := strings.Cut(trimmed, "=")
+		if ok && unquoteKey(strings.TrimSpace(key)) == name {
+			lines[i] = setting
+			return lines
+		}
+		insert = i + 1
+	}
+
+	if !found {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		return append(lines, "["+table+"]", setting)
+	}
+	return append(lines[:insert], append([]string{setting}, lines[insert:]...)...)
+}
+
+// formatValue renders a command-line value as TOML.
+func formatValue(v string) string {
+	if strings.HasPrefix(v, "[") || v == "true" || v == "false" {
+		return v
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	return strconv.Quote(v)
+}
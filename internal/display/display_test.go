@@ -63,3 +63,25 @@ func TestRelativeTimeEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestLineDiff(t *testing.T) {
+	old := "Add rate limiting\n\n- 429 above 100 req/s"
+	new := "Add rate limiting to the public API\n\n- 429 above 100 req/s\n- Retry-After header"
+
+	want := []string{
+		"- Add rate limiting",
+		"+ Add rate limiting to the public API",
+		"  ",
+		"  - 429 above 100 req/s",
+		"+ - Retry-After header",
+	}
+	got := LineDiff(old, new)
+	if len(got) != len(want) {
+		t.Fatalf("LineDiff() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("LineDiff()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package identity
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Current returns the person running craft: git's user.name, with
+// user.email in angle brackets when set, falling back to $USER (or
+// %USERNAME% on Windows). Empty if none is set.
+func Current() string {
+	if name := gitConfig("user.name"); name != "" {
+		if email := gitConfig("user.email"); email != "" {
+			return name + " <" + email + ">"
+		}
+		return name
+	}
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := strings.TrimSpace(os.Getenv(env)); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// Name returns the part of an identity before any email address, for
+// compact display.
+func Name(id string) string {
+	name, _, _ := strings.Cut(id, " <")
+	return name
+}
+
+// Email returns the email address in an identity, or "" if it has none.
+func Email(id string) string {
+	_, rest, ok := strings.Cut(id, " <")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSuffix(rest, ">")
+}
+
+// Same reports whether two identities are the same person: by email when
+// both have one, otherwise by name. Case is ignored.
+func Same(a, b string) bool {
+	if Email(a) != "" && Email(b) != "" {
+		return strings.EqualFold(Email(a), Email(b))
+	}
+	return strings.EqualFold(strings.TrimSpace(Name(a)), strings.TrimSpace(Name(b)))
+}
+
+func gitConfig(key string) string {
+	out, err := exec.Command("git", "config", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
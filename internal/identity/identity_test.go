@@ -0,0 +1,75 @@
+package identity
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCurrentFallsBackToUser(t *testing.T) {
+	// An empty global config hides any user.name set on this machine
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_SYSTEM", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Chdir(t.TempDir())
+	t.Setenv("USER", "alice")
+
+	if got := Current(); got != "alice" {
+		t.Errorf("Current() = %q, want alice", got)
+	}
+}
+
+// isolateGit hides any git identity configured on this machine.
+func isolateGit(t *testing.T) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_SYSTEM", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Chdir(t.TempDir())
+}
+
+func TestCurrentPrefersGit(t *testing.T) {
+	isolateGit(t)
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "user.name")
+	t.Setenv("GIT_CONFIG_VALUE_0", "Alice Liddell")
+	t.Setenv("USER", "alice")
+
+	if got := Current(); got != "Alice Liddell" {
+		t.Errorf("Current() = %q, want Alice Liddell", got)
+	}
+}
+
+func TestCurrentIncludesEmail(t *testing.T) {
+	isolateGit(t)
+	t.Setenv("GIT_CONFIG_COUNT", "2")
+	t.Setenv("GIT_CONFIG_KEY_0", "user.name")
+	t.Setenv("GIT_CONFIG_VALUE_0", "Alice Liddell")
+	t.Setenv("GIT_CONFIG_KEY_1", "user.email")
+	t.Setenv("GIT_CONFIG_VALUE_1", "alice@example.com")
+
+	want := "Alice Liddell <alice@example.com>"
+	if got := Current(); got != want {
+		t.Errorf("Current() = %q, want %q", got, want)
+	}
+	if got := Name(want); got != "Alice Liddell" {
+		t.Errorf("Name() = %q, want Alice Liddell", got)
+	}
+}
+
+func TestSame(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Alice", "alice", true},
+		{"Alice <alice@example.com>", "Alice", true},
+		{"Alice <alice@example.com>", "A. Liddell <ALICE@example.com>", true},
+		{"Alice <alice@example.com>", "Alice <alice@work.example>", false},
+		{"Alice", "Bob", false},
+	}
+	for _, tt := range tests {
+		if got := Same(tt.a, tt.b); got != tt.want {
+			t.Errorf("Same(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
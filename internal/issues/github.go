@@ -0,0 +1,123 @@
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"craft/internal/config"
+)
+
+// GitHub settings. The API URL can point at GitHub Enterprise or any
+// server speaking the same REST API; it's only read from the user config.
+const (
+	ConfigGitHubRepo   = "issues.github.repo"
+	ConfigGitHubAPIURL = config.KeyGitHubAPIURL
+
+	// EnvGitHubToken holds the token used to create issues.
+	EnvGitHubToken = "GITHUB_TOKEN"
+
+	defaultGitHubAPIURL = "https://api.github.com"
+)
+
+// GitHub creates issues through the GitHub REST API.
+type GitHub struct {
+	Repo   string // owner/name
+	APIURL string
+	Token  string
+	Client HTTPClient
+}
+
+func newGitHub(cfg *config.Config, client HTTPClient) (*GitHub, error) {
+	repo, _ := cfg.Get(ConfigGitHubRepo)
+	if strings.Count(repo, "/") != 1 {
+		return nil, fmt.Errorf("set %s to owner/name, e.g. craft config set %s acme/api", ConfigGitHubRepo, ConfigGitHubRepo)
+	}
+	apiURL, _ := cfg.Get(ConfigGitHubAPIURL)
+	if apiURL == "" {
+		apiURL = defaultGitHubAPIURL
+	}
+	token := os.Getenv(EnvGitHubToken)
+	if token == "" {
+		return nil, fmt.Errorf("set %s to a token that can create issues in %s", EnvGitHubToken, repo)
+	}
+	return &GitHub{Repo: repo, APIURL: strings.TrimSuffix(apiURL, "/"), Token: token, Client: client}, nil
+}
+
+func (g *GitHub) Name() string {
+	return ProviderGitHub
+}
+
+// githubIssue is the part of an issue sent and received.
+type githubIssue struct {
+	Title   string `json:"title,omitempty"`
+	Body    string `json:"body,omitempty"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+func (g *GitHub) Create(card Card) (string, error) {
+	var created githubIssue
+	err := g.call("POST", "/repos/"+g.Repo+"/issues", githubIssue{Title: card.Title, Body: card.Body}, &created)
+	if err != nil {
+		return "", err
+	}
+	if created.HTMLURL == "" {
+		return "", errors.New("response has no html_url")
+	}
+	return created.HTMLURL, nil
+}
+
+func (g *GitHub) Update(card Card) error {
+	// The issue number ends the URL: https://github.com/owner/name/issues/12
+	number := path.Base(card.URL)
+	if number == "" || strings.Trim(number, "0123456789") != "" {
+		return fmt.Errorf("%s is not a GitHub issue URL", card.URL)
+	}
+	return g.call("PATCH", "/repos/"+g.Repo+"/issues/"+number, githubIssue{Title: card.Title, Body: card.Body}, nil)
+}
+
+// call sends a JSON request and decodes the JSON reply into out, if given.
+func (g *GitHub) call(method, endpoint string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequest(method, g.APIURL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("GitHub API error: %s (%s)", apiErr.Message, resp.Status)
+		}
+		return fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
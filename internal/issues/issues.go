@@ -0,0 +1,165 @@
+package issues
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"craft/internal/config"
+	"craft/internal/structure"
+)
+
+// Provider names, as given to `craft export --issues`.
+const (
+	ProviderGitHub  = "github"
+	ProviderWebhook = "webhook"
+)
+
+// Providers lists the supported issue trackers.
+var Providers = []string{ProviderGitHub, ProviderWebhook}
+
+// requestTimeout bounds each call to the tracker.
+const requestTimeout = 30 * time.Second
+
+var (
+	titleRegex = regexp.MustCompile(`(?m)^#\s*Card:\s*(.+)$`)
+	headRegex  = regexp.MustCompile(`(?m)^#\s+(.+)$`)
+
+	// The issue URL is kept in the card file as an HTML comment, which
+	// Markdown viewers don't show.
+	issueRegex = regexp.MustCompile(`(?m)^<!-- issue: (\S+) -->\n?`)
+)
+
+// HTTPClient interface for testability.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Card is a card file as pushed to a tracker.
+type Card struct {
+	Path  string
+	Title string // From the "# Card:" heading, or the file name
+	Body  string // The card without its title and issue URL
+	URL   string // Issue created for the card, if any
+}
+
+// Provider creates and updates issues in a tracker.
+type Provider interface {
+	Name() string
+
+	// Create opens an issue for the card and returns its URL.
+	Create(card Card) (string, error)
+
+	// Update changes the issue at card.URL to match the card.
+	Update(card Card) error
+}
+
+// Result is the outcome of pushing one card.
+type Result struct {
+	Card    Card
+	Created bool // False if an existing issue was updated
+}
+
+// Get returns the named provider, configured from cfg.
+func Get(name string, cfg *config.Config, client HTTPClient) (Provider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	switch name {
+	case ProviderGitHub:
+		return newGitHub(cfg, client)
+	case ProviderWebhook:
+		return newWebhook(cfg, client)
+	default:
+		return nil, fmt.Errorf("unknown issue provider %q (use %s)", name, strings.Join(Providers, " or "))
+	}
+}
+
+// LoadCards reads every card file.
+func LoadCards() ([]Card, error) {
+	paths, err := structure.ListCards()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cards: %w", err)
+	}
+
+	var cards []Card
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read card: %w", err)
+		}
+		cards = append(cards, ParseCard(path, string(data)))
+	}
+	return cards, nil
+}
+
+// ParseCard splits a card file into its title, body and issue URL.
+func ParseCard(path, content string) Card {
+	c := Card{Path: path}
+	if m := issueRegex.FindStringSubmatch(content); m != nil {
+		c.URL = m[1]
+		content = issueRegex.ReplaceAllString(content, "")
+	}
+
+	title := titleRegex
+	if !title.MatchString(content) {
+		title = headRegex
+	}
+	if loc := title.FindStringSubmatchIndex(content); loc != nil {
+		c.Title = strings.TrimSpace(content[loc[2]:loc[3]])
+		content = content[:loc[0]] + content[loc[1]:]
+	} else {
+		c.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	c.Body = strings.TrimSpace(content)
+	return c
+}
+
+// Push creates an issue for each card without one, records its URL in the
+// card file, and updates the issues of the others. It stops at the first
+// failure; URLs recorded before it are kept, so running it again doesn't
+// duplicate issues.
+func Push(p Provider, cards []Card) ([]Result, error) {
+	var results []Result
+	for _, card := range cards {
+		if card.URL != "" {
+			if err := p.Update(card); err != nil {
+				return results, fmt.Errorf("failed to update %s: %w", card.URL, err)
+			}
+			results = append(results, Result{Card: card})
+			continue
+		}
+
+		url, err := p.Create(card)
+		if err != nil {
+			return results, fmt.Errorf("failed to create issue for %s: %w", filepath.Base(card.Path), err)
+		}
+		if url == "" || strings.ContainsAny(url, " \t\r\n") || strings.Contains(url, "-->") {
+			return results, fmt.Errorf("issue for %s was created, but its URL %q can't be recorded", filepath.Base(card.Path), url)
+		}
+		card.URL = url
+		if err := recordURL(card.Path, url); err != nil {
+			return results, err
+		}
+		results = append(results, Result{Card: card, Created: true})
+	}
+	return results, nil
+}
+
+// recordURL appends the issue URL to the card file.
+func recordURL(path, url string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read card: %w", err)
+	}
+	content := strings.TrimRight(string(data), "\n") + "\n\n<!-- issue: " + url + " -->\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to record issue URL in %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package issues
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"craft/internal/config"
+	"craft/internal/structure"
+	"craft/internal/workflow"
+)
+
+func setupTest(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv(workflow.EnvDir, "")
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+}
+
+func writeCard(t *testing.T, name, content string) string {
+	t.Helper()
+	if err := structure.EnsureStructureDir(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(structure.CardsDirPath(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func parseConfig(t *testing.T, toml string) *config.Config {
+	t.Helper()
+	cfg, err := config.Parse([]byte(toml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// fakeGitHub is a stand-in for the issues endpoints of the GitHub API.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	requests []string // "METHOD path"
+	issues   map[string]githubIssue
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Bad credentials"}`))
+		return
+	}
+	var in githubIssue
+	json.NewDecoder(r.Body).Decode(&in)
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/repos/acme/api/issues":
+		number := string(rune('1' + len(f.issues)))
+		f.issues[number] = in
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(githubIssue{HTMLURL: "https://github.com/acme/api/issues/" + number})
+	case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/repos/acme/api/issues/"):
+		number := filepath.Base(r.URL.Path)
+		if _, ok := f.issues[number]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+		f.issues[number] = in
+		json.NewEncoder(w).Encode(githubIssue{HTMLURL: "https://github.com/acme/api/issues/" + number})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestParseCard(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantTitle string
+		wantBody  string
+		wantURL   string
+	}{
+		{
+			name:      "card heading",
+			content:   "# Card: Add limiter\n\n## Tasks\n- [ ] Write it\n",
+			wantTitle: "Add limiter",
+			wantBody:  "## Tasks\n- [ ] Write it",
+		},
+		{
+			name:      "plain heading",
+			content:   "# Limiter\n\nBody\n",
+			wantTitle: "Limiter",
+			wantBody:  "Body",
+		},
+		{
+			name:      "no heading",
+			content:   "Just tasks\n",
+			wantTitle: "01-limiter",
+			wantBody:  "Just tasks",
+		},
+		{
+			name:      "recorded issue",
+			content:   "# Card: Add limiter\n\nBody\n\n<!-- issue: https://github.com/acme/api/issues/3 -->\n",
+			wantTitle: "Add limiter",
+			wantBody:  "Body",
+			wantURL:   "https://github.com/acme/api/issues/3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ParseCard("cards/01-limiter.md", tt.content)
+			if c.Title != tt.wantTitle || c.Body != tt.wantBody || c.URL != tt.wantURL {
+				t.Errorf("ParseCard() = %+v, want title %q, body %q, url %q", c, tt.wantTitle, tt.wantBody, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	t.Setenv(EnvGitHubToken, "")
+
+	if _, err := Get("jira", parseConfig(t, ""), nil); err == nil {
+		t.Error("Get(jira) should fail")
+	}
+	if _, err := Get(ProviderGitHub, parseConfig(t, ""), nil); err == nil || !strings.Contains(err.Error(), ConfigGitHubRepo) {
+		t.Errorf("Get(github) without repo error = %v, want it to name %s", err, ConfigGitHubRepo)
+	}
+	cfg := parseConfig(t, "[issues.github]\nrepo = \"acme/api\"\n")
+	if _, err := Get(ProviderGitHub, cfg, nil); err == nil || !strings.Contains(err.Error(), EnvGitHubToken) {
+		t.Errorf("Get(github) without token error = %v, want it to name %s", err, EnvGitHubToken)
+	}
+	if _, err := Get(ProviderWebhook, parseConfig(t, ""), nil); err == nil || !strings.Contains(err.Error(), ConfigWebhookURL) {
+		t.Errorf("Get(webhook) without URL error = %v, want it to name %s", err, ConfigWebhookURL)
+	}
+}
+
+func TestPushGitHub(t *testing.T) {
+	setupTest(t)
+	t.Setenv(EnvGitHubToken, "test-token")
+
+	fake := &fakeGitHub{issues: map[string]githubIssue{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	limiter := writeCard(t, "01-limiter.md", "# Card: Add limiter\n\n## Acceptance Criteria\n- 429 after 100 requests\n")
+	writeCard(t, "02-headers.md", "# Card: Send headers\n\n## Tasks\n- [ ] Retry-After\n")
+
+	cfg := parseConfig(t, "[issues.github]\nrepo = \"acme/api\"\napi_url = \""+server.URL+"/\"\n")
+	p, err := Get(ProviderGitHub, cfg, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	cards, _ := LoadCards()
+	results, err := Push(p, cards)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if len(results) != 2 || !results[0].Created || !results[1].Created {
+		t.Fatalf("Push() = %+v, want two created issues", results)
+	}
+	if got := fake.issues["1"]; got.Title != "Add limiter" || !strings.Contains(got.Body, "429 after 100 requests") {
+		t.Errorf("issue 1 = %+v, want the card's title and criteria", got)
+	}
+
+	data, _ := os.ReadFile(limiter)
+	if !strings.Contains(string(data), "<!-- issue: https://github.com/acme/api/issues/1 -->") {
+		t.Errorf("card = %q, want the issue URL recorded", data)
+	}
+
+	// A second push updates the same issues instead of opening new ones
+	os.WriteFile(limiter, []byte(strings.Replace(string(data), "100", "50", 1)), 0644)
+	cards, _ = LoadCards()
+	results, err = Push(p, cards)
+	if err != nil {
+		t.Fatalf("Push() again error = %v", err)
+	}
+	if len(fake.issues) != 2 || results[0].Created || results[1].Created {
+		t.Errorf("Push() again = %+v with %d issues, want two updates", results, len(fake.issues))
+	}
+	if got := fake.issues["1"]; !strings.Contains(got.Body, "429 after 50 requests") || strings.Contains(got.Body, "<!-- issue") {
+		t.Errorf("updated issue body = %q, want the edited card without its URL", got.Body)
+	}
+	if got := fake.requests[len(fake.requests)-2]; got != "PATCH /repos/acme/api/issues/1" {
+		t.Errorf("request = %q, want a PATCH of issue 1", got)
+	}
+	data, _ = os.ReadFile(limiter)
+	if n := strings.Count(string(data), "<!-- issue:"); n != 1 {
+		t.Errorf("card records %d issue URLs, want 1", n)
+	}
+}
+
+func TestPushGitHubError(t *testing.T) {
+	setupTest(t)
+	t.Setenv(EnvGitHubToken, "wrong")
+
+	fake := &fakeGitHub{issues: map[string]githubIssue{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	path := writeCard(t, "01-limiter.md", "# Card: Add limiter\n")
+	cfg := parseConfig(t, "[issues.github]\nrepo = \"acme/api\"\napi_url = \""+server.URL+"\"\n")
+	p, _ := Get(ProviderGitHub, cfg, nil)
+
+	cards, _ := LoadCards()
+	_, err := Push(p, cards)
+	if err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("Push() error = %v, want the API message", err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "<!-- issue") {
+		t.Errorf("card = %q, want no URL recorded after a failure", data)
+	}
+}
+
+func TestPushWebhook(t *testing.T) {
+	setupTest(t)
+	t.Setenv(EnvWebhookToken, "hook-token")
+
+	var received []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hook-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var in webhookRequest
+		json.NewDecoder(r.Body).Decode(&in)
+		received = append(received, in)
+		if in.Action == actionCreate {
+			json.NewEncoder(w).Encode(webhookResponse{URL: "https://tracker.example/T-1"})
+		}
+	}))
+	defer server.Close()
+
+	path := writeCard(t, "01-limiter.md", "# Card: Add limiter\n\nBody\n")
+	cfg := parseConfig(t, "[issues.webhook]\nurl = \""+server.URL+"\"\n")
+	p, err := Get(ProviderWebhook, cfg, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	cards, _ := LoadCards()
+	if _, err := Push(p, cards); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	cards, _ = LoadCards()
+	if _, err := Push(p, cards); err != nil {
+		t.Fatalf("Push() again error = %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("webhook received %d requests, want 2", len(received))
+	}
+	want := webhookRequest{Action: actionCreate, Card: "01-limiter.md", Title: "Add limiter", Body: "Body"}
+	if received[0] != want {
+		t.Errorf("create request = %+v, want %+v", received[0], want)
+	}
+	want.Action, want.URL = actionUpdate, "https://tracker.example/T-1"
+	if received[1] != want {
+		t.Errorf("update request = %+v, want %+v", received[1], want)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "<!-- issue: https://tracker.example/T-1 -->") {
+		t.Errorf("card = %q, want the issue URL recorded", data)
+	}
+}
+
+func TestPushWebhookWithoutURL(t *testing.T) {
+	setupTest(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	writeCard(t, "01-limiter.md", "# Card: Add limiter\n")
+	cfg := parseConfig(t, "[issues.webhook]\nurl = \""+server.URL+"\"\n")
+	p, _ := Get(ProviderWebhook, cfg, nil)
+
+	cards, _ := LoadCards()
+	if _, err := Push(p, cards); err == nil {
+		t.Error("Push() should fail when the webhook reply has no URL")
+	}
+}
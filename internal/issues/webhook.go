@@ -0,0 +1,119 @@
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"craft/internal/config"
+)
+
+// Webhook settings. The URL is only read from the user config.
+const (
+	ConfigWebhookURL = config.KeyWebhookURL
+
+	// EnvWebhookToken, if set, is sent as a bearer token.
+	EnvWebhookToken = "CRAFT_ISSUES_WEBHOOK_TOKEN"
+)
+
+// Webhook actions.
+const (
+	actionCreate = "create"
+	actionUpdate = "update"
+)
+
+// Webhook posts each card as JSON to a URL, for trackers without a
+// built-in provider. Creating must reply with {"url": "..."}.
+type Webhook struct {
+	URL    string
+	Token  string
+	Client HTTPClient
+}
+
+func newWebhook(cfg *config.Config, client HTTPClient) (*Webhook, error) {
+	url, _ := cfg.Get(ConfigWebhookURL)
+	if url == "" {
+		return nil, fmt.Errorf("set %s to the URL that creates issues, e.g. craft config set --user %s https://...", ConfigWebhookURL, ConfigWebhookURL)
+	}
+	return &Webhook{URL: url, Token: os.Getenv(EnvWebhookToken), Client: client}, nil
+}
+
+func (h *Webhook) Name() string {
+	return ProviderWebhook
+}
+
+// webhookRequest is the JSON body posted for each card.
+type webhookRequest struct {
+	Action string `json:"action"` // create or update
+	Card   string `json:"card"`   // File name, e.g. 01-limiter.md
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	URL    string `json:"url,omitempty"` // Set when updating
+}
+
+type webhookResponse struct {
+	URL string `json:"url"`
+}
+
+func (h *Webhook) Create(card Card) (string, error) {
+	var resp webhookResponse
+	if err := h.post(h.request(actionCreate, card), &resp); err != nil {
+		return "", err
+	}
+	if resp.URL == "" {
+		return "", errors.New(`webhook reply has no "url"`)
+	}
+	return resp.URL, nil
+}
+
+func (h *Webhook) Update(card Card) error {
+	return h.post(h.request(actionUpdate, card), nil)
+}
+
+func (h *Webhook) request(action string, card Card) webhookRequest {
+	return webhookRequest{
+		Action: action,
+		Card:   filepath.Base(card.Path),
+		Title:  card.Title,
+		Body:   card.Body,
+		URL:    card.URL,
+	}
+}
+
+func (h *Webhook) post(in webhookRequest, out *webhookResponse) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode webhook reply: %w", err)
+	}
+	return nil
+}
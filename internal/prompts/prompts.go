@@ -0,0 +1,137 @@
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"craft/internal/workflow"
+)
+
+//go:embed files/*.tmpl
+var defaults embed.FS
+
+// Prompt template names.
+const (
+	Review = "review"
+	Pitch  = "pitch"
+	Cards  = "cards"
+)
+
+// PromptsDir is the project directory holding template overrides.
+const PromptsDir = "prompts"
+
+// Names lists every prompt template, in display order.
+var Names = []string{Review, Pitch, Cards}
+
+// Data is the value passed to every prompt template.
+type Data struct {
+	Intent  string
+	Notes   []string
+	Pitch   string // Generated pitch; only set for the cards prompt
+	Context string // Repository context, already fitted to the token budget
+	Repo    Repo
+}
+
+// Repo describes the repository the workflow lives in.
+type Repo struct {
+	Name   string // Base name of the repository root
+	Root   string // Absolute path of the repository root
+	Branch string // Current git branch, empty outside git
+}
+
+// Valid returns true if name is a known template.
+func Valid(name string) bool {
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Dir returns the path to the project prompt override directory.
+func Dir() string {
+	return filepath.Join(workflow.Dir(), PromptsDir)
+}
+
+// Path returns the project override path for the named template.
+func Path(name string) string {
+	return filepath.Join(Dir(), name+".tmpl")
+}
+
+// Default returns the embedded default template.
+func Default(name string) ([]byte, error) {
+	if !Valid(name) {
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+	return defaults.ReadFile("files/" + name + ".tmpl")
+}
+
+// Overridden returns true if the project provides its own template.
+func Overridden(name string) bool {
+	info, err := os.Stat(Path(name))
+	return err == nil && !info.IsDir()
+}
+
+// Load returns the project template if present, otherwise the default.
+func Load(name string) ([]byte, error) {
+	if !Valid(name) {
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+	data, err := os.ReadFile(Path(name))
+	if err == nil {
+		return data, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", Path(name), err)
+	}
+	return Default(name)
+}
+
+// Render executes the named template with data.
+func Render(name string, data Data) (string, error) {
+	src, err := Load(name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(src))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s prompt template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", name, err)
+	}
+
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// CurrentRepo describes the repository containing the working directory.
+// Outside git, the working directory itself is used as the root.
+func CurrentRepo() Repo {
+	root := gitOutput("rev-parse", "--show-toplevel")
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	return Repo{
+		Name:   filepath.Base(root),
+		Root:   root,
+		Branch: gitOutput("rev-parse", "--abbrev-ref", "HEAD"),
+	}
+}
+
+func gitOutput(args ...string) string {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,100 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupTest(t *testing.T) func() {
+	t.Helper()
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return func() {
+		os.Chdir(origDir)
+	}
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	for _, name := range Names {
+		content, err := Default(name)
+		if err != nil {
+			t.Errorf("Default(%q) error = %v", name, err)
+			continue
+		}
+		if len(content) == 0 {
+			t.Errorf("Default(%q) is empty", name)
+		}
+	}
+
+	if _, err := Default("unknown"); err == nil {
+		t.Error("Default(unknown) should return error")
+	}
+}
+
+func TestRenderDefault(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	out, err := Render(Review, Data{Intent: "Add rate limiting", Notes: []string{"Token bucket"}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, "Intent: Add rate limiting") {
+		t.Error("review prompt should contain intent")
+	}
+	if !strings.Contains(out, "- Token bucket") {
+		t.Error("review prompt should contain notes")
+	}
+	if strings.HasSuffix(out, "\n") {
+		t.Error("rendered prompt should not end with newline")
+	}
+}
+
+func TestRenderProjectOverride(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	os.MkdirAll(Dir(), 0755)
+	tmpl := "{{.Intent}} in {{.Repo.Name}}\nEvery card must include a rollback plan.\n"
+	os.WriteFile(Path(Cards), []byte(tmpl), 0644)
+
+	if !Overridden(Cards) {
+		t.Error("Overridden(cards) = false, want true")
+	}
+	if Overridden(Pitch) {
+		t.Error("Overridden(pitch) = true, want false")
+	}
+
+	out, err := Render(Cards, Data{Intent: "Ship it", Repo: Repo{Name: "craft"}})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := "Ship it in craft\nEvery card must include a rollback plan."
+	if out != want {
+		t.Errorf("Render() = %q, want %q", out, want)
+	}
+}
+
+func TestRenderInvalidOverride(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	os.MkdirAll(Dir(), 0755)
+	os.WriteFile(Path(Review), []byte("{{.Intent"), 0644)
+
+	if _, err := Render(Review, Data{}); err == nil {
+		t.Error("Render() with invalid template should return error")
+	}
+
+	os.WriteFile(Path(Review), []byte("{{.Missing}}"), 0644)
+	if _, err := Render(Review, Data{}); err == nil {
+		t.Error("Render() referencing unknown field should return error")
+	}
+}
@@ -0,0 +1,288 @@
+package repocontext
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"craft/internal/workflow"
+)
+
+const (
+	// ContextFile lists extra files to include, one glob per line.
+	ContextFile = "context"
+
+	envBudget     = "CRAFT_CONTEXT_BUDGET"
+	defaultBudget = 4000 // tokens
+
+	charsPerToken = 4
+	treeMaxDepth  = 3
+	treeMaxLines  = 200
+	truncatedMark = "\n... (truncated)"
+)
+
+// TreeTitle heads the directory tree section, which changes whenever a
+// file is added or removed.
+const TreeTitle = "Directory tree"
+
+// Manifests are the module manifests recognised at the repository root.
+var Manifests = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "Gemfile"}
+
+// readmes are the README names tried, in order.
+var readmes = []string{"README.md", "README", "README.txt"}
+
+// skipDirs are never descended into when building the tree or matching globs.
+var skipDirs = map[string]bool{
+	".git":           true,
+	workflow.DirName: true,
+	"node_modules":   true,
+	"vendor":         true,
+}
+
+// Section is one named piece of repository context.
+type Section struct {
+	Title   string
+	Content string
+}
+
+// Budget returns the configured token budget. Zero disables context.
+func Budget() int {
+	if v := os.Getenv(envBudget); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return defaultBudget
+}
+
+// ContextPath returns the path to the user-selected file list.
+func ContextPath() string {
+	return filepath.Join(workflow.Dir(), ContextFile)
+}
+
+// Collect gathers repository context rooted at root and formats it within
+// budget tokens. Sections are included by priority: user-selected files,
+// manifests, the directory tree, then the README. The first section that
+// does not fit is truncated and the rest are dropped.
+func Collect(root string, budget int) string {
+	if budget <= 0 {
+		return ""
+	}
+	sections, err := Gather(root)
+	if err != nil {
+		return ""
+	}
+	return Fit(sections, budget)
+}
+
+// Gather reads every context section available under root, in priority order.
+func Gather(root string) ([]Section, error) {
+	var sections []Section
+
+	selected, err := selectedFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range selected {
+		if s, ok := fileSection(root, rel); ok {
+			sections = append(sections, s)
+		}
+	}
+
+	for _, name := range Manifests {
+		if s, ok := fileSection(root, name); ok {
+			sections = append(sections, s)
+		}
+	}
+
+	if tree := Tree(root); tree != "" {
+		sections = append(sections, Section{Title: TreeTitle, Content: tree})
+	}
+
+	for _, name := range readmes {
+		if s, ok := fileSection(root, name); ok {
+			sections = append(sections, s)
+			break
+		}
+	}
+
+	return dedupe(sections), nil
+}
+
+// Fit formats sections as markdown, stopping once budget tokens are used.
+func Fit(sections []Section, budget int) string {
+	remaining := budget * charsPerToken
+	var sb strings.Builder
+
+	for _, s := range sections {
+		block := formatSection(s)
+		if len(block) <= remaining {
+			sb.WriteString(block)
+			remaining -= len(block)
+			continue
+		}
+
+		// Truncate this section to what's left, then stop
+		overhead := len(formatSection(Section{Title: s.Title})) + len(truncatedMark)
+		if room := remaining - overhead; room > 0 {
+			cut := s.Content[:room]
+			if i := strings.LastIndex(cut, "\n"); i > 0 {
+				cut = cut[:i]
+			}
+			sb.WriteString(formatSection(Section{Title: s.Title, Content: cut + truncatedMark}))
+		}
+		break
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func formatSection(s Section) string {
+	return fmt.Sprintf("### %s\n```\n%s\n```\n\n", s.Title, strings.TrimRight(s.Content, "\n"))
+}
+
+// Tree returns an indented listing of root, limited in depth and length.
+func Tree(root string) string {
+	var lines []string
+	truncated := false
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		depth := strings.Count(filepath.ToSlash(rel), "/")
+
+		if d.IsDir() && (skipDirs[d.Name()] || depth >= treeMaxDepth) {
+			return filepath.SkipDir
+		}
+		if len(lines) >= treeMaxLines {
+			truncated = true
+			return filepath.SkipAll
+		}
+
+		name := d.Name()
+		if d.IsDir() {
+			name += "/"
+		}
+		lines = append(lines, strings.Repeat("  ", depth)+name)
+		return nil
+	})
+
+	if truncated {
+		lines = append(lines, "...")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// selectedFiles expands the globs in .craft/context into sorted relative paths.
+func selectedFiles(root string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, workflow.DirName, ContextFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var patterns []*regexp.Regexp
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, globToRegexp(line))
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	var matches []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		for _, re := range patterns {
+			if re.MatchString(rel) {
+				matches = append(matches, rel)
+				break
+			}
+		}
+		return nil
+	})
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// globToRegexp converts a glob with *, ? and ** into an anchored regexp.
+func globToRegexp(glob string) *regexp.Regexp {
+	glob = strings.TrimPrefix(filepath.ToSlash(glob), "./")
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				i++
+				sb.WriteString("(?:.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// fileSection reads a text file relative to root.
+func fileSection(root, rel string) (Section, bool) {
+	data, err := os.ReadFile(filepath.Join(root, rel))
+	if err != nil || len(data) == 0 || isBinary(data) {
+		return Section{}, false
+	}
+	return Section{Title: filepath.ToSlash(rel), Content: string(data)}, true
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// dedupe drops repeated sections, keeping the higher-priority one.
+func dedupe(sections []Section) []Section {
+	seen := make(map[string]bool)
+	var out []Section
+	for _, s := range sections {
+		if seen[s.Title] {
+			continue
+		}
+		seen[s.Title] = true
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package repocontext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGatherOrder(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "README.md", "# Project")
+	writeFile(t, root, "go.mod", "module example")
+	writeFile(t, root, "internal/api/limit.go", "package api")
+	writeFile(t, root, ".craft/context", "# comment\ninternal/**/*.go\n")
+
+	sections, err := Gather(root)
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	var titles []string
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+	}
+	want := []string{"internal/api/limit.go", "go.mod", "Directory tree", "README.md"}
+	if strings.Join(titles, ",") != strings.Join(want, ",") {
+		t.Errorf("sections = %v, want %v", titles, want)
+	}
+}
+
+func TestTreeSkipsCraftAndGit(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main")
+	writeFile(t, root, ".git/HEAD", "ref")
+	writeFile(t, root, ".craft/workflow.md", "---")
+	writeFile(t, root, "cmd/start.go", "package cmd")
+
+	tree := Tree(root)
+	if strings.Contains(tree, ".git") || strings.Contains(tree, ".craft") {
+		t.Errorf("Tree() should skip .git and .craft, got:\n%s", tree)
+	}
+	if !strings.Contains(tree, "cmd/\n  start.go") {
+		t.Errorf("Tree() should indent nested files, got:\n%s", tree)
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/start.go", false},
+		{"cmd/*.go", "cmd/start.go", true},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "internal/a/b.go", true},
+		{"docs/**", "docs/a/b.md", true},
+		{"./README.md", "README.md", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+	}
+
+	for _, tt := range tests {
+		if got := globToRegexp(tt.glob).MatchString(tt.path); got != tt.match {
+			t.Errorf("glob %q vs %q = %v, want %v", tt.glob, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestFitTruncates(t *testing.T) {
+	sections := []Section{
+		{Title: "first", Content: "short"},
+		{Title: "second", Content: strings.Repeat("line of text\n", 100)},
+		{Title: "third", Content: "never included"},
+	}
+
+	out := Fit(sections, 50)
+	if !strings.Contains(out, "### first") {
+		t.Error("Fit() should include the first section")
+	}
+	if !strings.Contains(out, "(truncated)") {
+		t.Error("Fit() should mark the truncated section")
+	}
+	if strings.Contains(out, "third") {
+		t.Error("Fit() should drop sections after the truncated one")
+	}
+	if len(out) > 50*charsPerToken {
+		t.Errorf("Fit() length = %d, exceeds budget of %d chars", len(out), 50*charsPerToken)
+	}
+}
+
+func TestCollectDisabled(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "go.mod", "module example")
+
+	if got := Collect(root, 0); got != "" {
+		t.Errorf("Collect() with zero budget = %q, want empty", got)
+	}
+	if got := Collect(root, 1000); !strings.Contains(got, "module example") {
+		t.Errorf("Collect() should include go.mod, got %q", got)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	os.Unsetenv(envBudget)
+	if got := Budget(); got != defaultBudget {
+		t.Errorf("Budget() = %d, want %d", got, defaultBudget)
+	}
+
+	os.Setenv(envBudget, "0")
+	defer os.Unsetenv(envBudget)
+	if got := Budget(); got != 0 {
+		t.Errorf("Budget() = %d, want 0", got)
+	}
+
+	os.Setenv(envBudget, "nonsense")
+	if got := Budget(); got != defaultBudget {
+		t.Errorf("Budget() with invalid value = %d, want %d", got, defaultBudget)
+	}
+}
@@ -5,15 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
+
+	"craft/internal/cassette"
+	"craft/internal/config"
+	"craft/internal/prompts"
+	"craft/internal/secret"
+	"craft/internal/usage"
 )
 
 const (
-	envAPIKey      = "CRAFT_AI_API_KEY"
-	envModel       = "CRAFT_AI_MODEL"
-	envBaseURL     = "CRAFT_AI_BASE_URL"
 	defaultModel   = "gpt-4o-mini"
 	defaultBaseURL = "https://api.openai.com/v1"
 )
@@ -33,63 +35,63 @@ func (r *AIReviewer) Name() string {
 }
 
 func (r *AIReviewer) Available() bool {
-	return os.Getenv(envAPIKey) != ""
+	return secret.Configured() || cassette.Replaying()
 }
 
 func (r *AIReviewer) Review(req ReviewRequest) (ReviewResponse, error) {
-	apiKey := os.Getenv(envAPIKey)
-	if apiKey == "" {
-		return ReviewResponse{}, fmt.Errorf("%s not set", envAPIKey)
+	cfg, err := config.Load()
+	if err != nil {
+		return ReviewResponse{}, err
+	}
+
+	var apiKey string
+	if !cassette.Replaying() {
+		apiKey, err = secret.APIKey()
+		if err != nil {
+			return ReviewResponse{}, err
+		}
 	}
 
-	model := os.Getenv(envModel)
+	model, _ := cfg.Get(config.KeyAIModel)
 	if model == "" {
 		model = defaultModel
 	}
 
-	baseURL := os.Getenv(envBaseURL)
+	baseURL, _ := cfg.Get(config.KeyAIBaseURL)
 	if baseURL == "" {
 		baseURL = defaultBaseURL
 	}
 
 	client := r.Client
 	if client == nil {
-		client = &http.Client{Timeout: 60 * time.Second}
+		client = cassette.Wrap(&http.Client{Timeout: 60 * time.Second})
+	}
+
+	prompt, err := buildPrompt(req)
+	if err != nil {
+		return ReviewResponse{}, err
 	}
 
-	prompt := buildPrompt(req)
-	content, err := callAPI(client, baseURL, apiKey, model, prompt)
+	content, call, err := callAPI(client, baseURL, apiKey, model, prompt)
 	if err != nil {
 		return ReviewResponse{}, fmt.Errorf("AI review failed: %w", err)
 	}
+	call.Purpose = usage.PurposeReview
 
 	return ReviewResponse{
 		Content:  content,
 		Reviewer: NameAI,
+		Usage:    []usage.Call{call},
 	}, nil
 }
 
-func buildPrompt(req ReviewRequest) string {
-	var sb strings.Builder
-	sb.WriteString("You are reviewing a software development intent before implementation begins.\n\n")
-	sb.WriteString(fmt.Sprintf("Intent: %s\n\n", req.Intent))
-
-	sb.WriteString("Notes so far:\n")
-	if len(req.Notes) == 0 {
-		sb.WriteString("(none)\n")
-	} else {
-		for _, note := range req.Notes {
-			sb.WriteString(fmt.Sprintf("- %s\n", note))
-		}
-	}
-
-	sb.WriteString("\nPlease review this intent and provide:\n")
-	sb.WriteString("1. Clarifying questions the developer should consider\n")
-	sb.WriteString("2. Potential concerns or risks\n")
-	sb.WriteString("3. Suggestions for scope refinement\n\n")
-	sb.WriteString("Be direct and constructive. Focus on helping the developer think clearly.")
-
-	return sb.String()
+func buildPrompt(req ReviewRequest) (string, error) {
+	return prompts.Render(prompts.Review, prompts.Data{
+		Intent:  req.Intent,
+		Notes:   req.Notes,
+		Context: req.Context,
+		Repo:    prompts.CurrentRepo(),
+	})
 }
 
 type chatRequest struct {
@@ -103,17 +105,24 @@ type chatMessage struct {
 }
 
 type chatResponse struct {
+	Model   string `json:"model"`
 	Choices []struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
 	Error *struct {
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
-func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string, error) {
+// callAPI sends prompt to the chat completions endpoint and returns the
+// reply with the tokens it consumed. Purpose is left for the caller to set.
+func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string, usage.Call, error) {
 	reqBody := chatRequest{
 		Model: model,
 		Messages: []chatMessage{
@@ -123,13 +132,13 @@ func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string,
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", usage.Call{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
 	httpReq, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", usage.Call{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -137,22 +146,34 @@ func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string,
 
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return "", usage.Call{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var chatResp chatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return "", usage.Call{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if chatResp.Error != nil {
-		return "", fmt.Errorf("API error: %s", chatResp.Error.Message)
+		return "", usage.Call{}, fmt.Errorf("API error: %s", chatResp.Error.Message)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("no response from AI")
+		return "", usage.Call{}, fmt.Errorf("no response from AI")
+	}
+
+	call := usage.Call{
+		Model: chatResp.Model,
+		At:    time.Now().UTC(),
+	}
+	if call.Model == "" {
+		call.Model = model
+	}
+	if chatResp.Usage != nil {
+		call.PromptTokens = chatResp.Usage.PromptTokens
+		call.CompletionTokens = chatResp.Usage.CompletionTokens
 	}
 
-	return chatResp.Choices[0].Message.Content, nil
+	return chatResp.Choices[0].Message.Content, call, nil
 }
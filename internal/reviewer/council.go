@@ -1,11 +1,19 @@
 package reviewer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"craft/internal/runner"
 )
 
+// maxArgInput is the largest review input passed as an argument. Larger
+// input is sent on stdin (`council review -`) to stay under argv limits.
+const maxArgInput = 32 * 1024
+
 // CouncilReviewer invokes council-cli for multi-perspective review.
 type CouncilReviewer struct{}
 
@@ -29,21 +37,25 @@ func (r *CouncilReviewer) Review(req ReviewRequest) (ReviewResponse, error) {
 		}
 	}
 
-	cmd := exec.Command("council", "review", input.String())
-	output, err := cmd.Output()
+	spec := runner.Spec{Name: "council", Path: "council"}
+	if input.Len() > maxArgInput {
+		spec.Args = []string{"review", "-"}
+		spec.Stdin = []byte(input.String())
+	} else {
+		spec.Args = []string{"review", input.String()}
+	}
+
+	result, err := runner.Run(context.Background(), spec)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exitErr.Stderr)
-			if strings.Contains(stderr, "unknown command") {
-				return ReviewResponse{}, fmt.Errorf("council review command not available (council-cli may need updating)")
-			}
-			return ReviewResponse{}, fmt.Errorf("council failed: %s", stderr)
+		var exitErr *runner.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(exitErr.Stderr, "unknown command") {
+			return ReviewResponse{}, fmt.Errorf("council review command not available (council-cli may need updating)")
 		}
-		return ReviewResponse{}, fmt.Errorf("council failed: %w", err)
+		return ReviewResponse{}, err
 	}
 
 	return ReviewResponse{
-		Content:  strings.TrimSpace(string(output)),
+		Content:  strings.TrimSpace(string(result.Stdout)),
 		Reviewer: NameCouncil,
 	}, nil
 }
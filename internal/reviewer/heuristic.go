@@ -0,0 +1,122 @@
+package reviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Word-count bounds outside which an intent is flagged.
+const (
+	minIntentWords = 4
+	maxIntentWords = 40
+)
+
+// vagueVerbs are verbs that say work will happen without saying what changes.
+var vagueVerbs = []string{
+	"improve", "refactor", "clean up", "cleanup", "optimize", "optimise",
+	"enhance", "update", "fix", "handle", "rework", "tweak", "polish", "revisit",
+}
+
+// vagueNouns are placeholders that hide the actual subject.
+var vagueNouns = []string{"stuff", "things", "misc", "various", "etc"}
+
+// measurePattern matches a quantity with a unit, such as "200ms", "5%" or
+// "100 req/s". A bare number, as in "OAuth2" or "v3", is not a criterion.
+const measurePattern = `\d+(?:\.\d+)?\s*(?:%|(?:ms|s|x|rps|req/s|[kmg]b)\b)`
+
+var (
+	vagueVerbRegex  = wordsRegex(vagueVerbs)
+	vagueNounRegex  = wordsRegex(vagueNouns)
+	wordRegex       = regexp.MustCompile(`[A-Za-z0-9']+`)
+	compoundRegex   = regexp.MustCompile(`(?i)\b(and|also|plus|as well as)\b|&`)
+	successRegex    = regexp.MustCompile(`(?i)\b(?:so that|done when|success\w*|measur\w*|metrics?|until|targets?|acceptance|criteria)\b|` + measurePattern)
+	constraintRegex = regexp.MustCompile(`(?i)\b(?:must|without|only|within|no more than|at most|max(?:imum)?|budget|deadline|constraints?|compatib\w*|don't|do not|never)\b`)
+)
+
+// HeuristicReviewer applies local rules to the intent and notes. It needs no
+// network or external tools, so it is always available.
+type HeuristicReviewer struct{}
+
+func (r *HeuristicReviewer) Name() string {
+	return NameHeuristic
+}
+
+func (r *HeuristicReviewer) Available() bool {
+	return true
+}
+
+func (r *HeuristicReviewer) Review(req ReviewRequest) (ReviewResponse, error) {
+	findings := Analyze(req)
+
+	var sb strings.Builder
+	if len(findings) == 0 {
+		sb.WriteString("No obvious gaps found in the intent.\n\n")
+		sb.WriteString("Still worth asking:\n")
+		sb.WriteString("- Is this the smallest useful version?\n")
+		sb.WriteString("- What could go wrong?\n")
+	} else {
+		sb.WriteString("Questions from a local review of the intent:\n")
+		for _, f := range findings {
+			sb.WriteString("- " + f + "\n")
+		}
+	}
+	sb.WriteString("\nFor a deeper review: set CRAFT_AI_API_KEY or install council-cli")
+
+	return ReviewResponse{
+		Content:  sb.String(),
+		Reviewer: NameHeuristic,
+	}, nil
+}
+
+// Analyze returns a specific question for each weakness found in the intent.
+func Analyze(req ReviewRequest) []string {
+	intent := strings.TrimSpace(req.Intent)
+	lower := strings.ToLower(intent)
+	all := strings.ToLower(intent + "\n" + strings.Join(req.Notes, "\n"))
+
+	var findings []string
+
+	// Length and scope are judged on the first line; the rest of a
+	// multi-line intent is context, like notes
+	headline, _, _ := strings.Cut(intent, "\n")
+
+	words := wordRegex.FindAllString(headline, -1)
+	switch {
+	case len(words) < minIntentWords:
+		findings = append(findings, fmt.Sprintf("The intent is only %d word(s). What problem does it solve, and for whom?", len(words)))
+	case len(words) > maxIntentWords:
+		findings = append(findings, fmt.Sprintf("The intent is %d words long. Can you state it in one sentence and move the rest to notes?", len(words)))
+	}
+
+	if verb := vagueVerbRegex.FindString(lower); verb != "" {
+		findings = append(findings, fmt.Sprintf("%q is vague. What will be observably different when this is done?", verb))
+	}
+
+	if noun := vagueNounRegex.FindString(lower); noun != "" {
+		findings = append(findings, fmt.Sprintf("%q hides the subject. Which parts exactly are in scope?", noun))
+	}
+
+	if m := compoundRegex.FindString(headline); m != "" {
+		findings = append(findings, fmt.Sprintf("The intent joins work with %q. Is this one change or two that could ship separately?", strings.ToLower(m)))
+	}
+
+	if !successRegex.MatchString(all) {
+		findings = append(findings, "No success criteria. How will you know this is done?")
+	}
+
+	if !constraintRegex.MatchString(all) {
+		findings = append(findings, "No constraints stated. What must not change, and what limits (time, compatibility, cost) apply?")
+	}
+
+	return findings
+}
+
+// wordsRegex matches any of phrases on word boundaries.
+func wordsRegex(phrases []string) *regexp.Regexp {
+	quoted := make([]string, len(phrases))
+	for i, p := range phrases {
+		quoted[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
@@ -0,0 +1,62 @@
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"craft/internal/runner"
+)
+
+// PluginPrefix names reviewer plugins on PATH: craft-reviewer-<name>.
+const PluginPrefix = "craft-reviewer-"
+
+// PluginReviewer runs an external executable that speaks the reviewer
+// protocol: it receives a ReviewRequest as JSON on stdin and writes a
+// ReviewResponse as JSON to stdout.
+type PluginReviewer struct {
+	PluginName string
+	Path       string
+}
+
+func (r *PluginReviewer) Name() string {
+	return r.PluginName
+}
+
+func (r *PluginReviewer) Available() bool {
+	return runner.IsExecutable(r.Path)
+}
+
+func (r *PluginReviewer) Review(req ReviewRequest) (ReviewResponse, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return ReviewResponse{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	result, err := runner.Run(context.Background(), runner.Spec{
+		Name:  "reviewer " + r.PluginName,
+		Path:  r.Path,
+		Stdin: input,
+	})
+	if err != nil {
+		return ReviewResponse{}, err
+	}
+
+	var resp ReviewResponse
+	if err := json.Unmarshal(result.Stdout, &resp); err != nil {
+		return ReviewResponse{}, fmt.Errorf("reviewer %s returned invalid JSON: %w", r.PluginName, err)
+	}
+	if strings.TrimSpace(resp.Content) == "" {
+		return ReviewResponse{}, fmt.Errorf("reviewer %s returned no content", r.PluginName)
+	}
+	if resp.Reviewer == "" {
+		resp.Reviewer = r.PluginName
+	}
+	return resp, nil
+}
+
+// DiscoverPlugins finds craft-reviewer-<name> executables on PATH.
+func DiscoverPlugins() map[string]string {
+	return runner.Discover(PluginPrefix)
+}
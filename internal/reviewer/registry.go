@@ -0,0 +1,119 @@
+package reviewer
+
+import (
+	"fmt"
+	"sort"
+
+	"craft/internal/config"
+	"craft/internal/secret"
+)
+
+// Config keys for reviewer selection.
+const (
+	ConfigPriority = "reviewers.priority"
+	ConfigPlugins  = config.KeyReviewerPlugins
+)
+
+// DefaultPriority is the auto-detection order when the project sets none.
+var DefaultPriority = []string{FlagCouncil, FlagAI, FlagHeuristic, FlagNone}
+
+// builtinNames lists built-in reviewers in display order.
+var builtinNames = []string{FlagCouncil, FlagAI, FlagHeuristic, FlagNone}
+
+// Registry holds every reviewer selectable by name, built-in or plugin.
+type Registry struct {
+	reviewers map[string]Reviewer
+	plugins   []string
+	priority  []string
+}
+
+// LoadRegistry builds the registry from PATH and the configuration.
+func LoadRegistry() (*Registry, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(cfg), nil
+}
+
+// NewRegistry builds a registry from built-ins, plugins discovered on PATH,
+// and plugins listed under [reviewers.plugins] in cfg. A nil cfg uses defaults.
+func NewRegistry(cfg *config.Config) *Registry {
+	r := &Registry{
+		reviewers: map[string]Reviewer{
+			FlagCouncil:   &CouncilReviewer{},
+			FlagAI:        &AIReviewer{},
+			FlagHeuristic: &HeuristicReviewer{},
+			FlagNone:      &NullReviewer{},
+		},
+		priority: DefaultPriority,
+	}
+
+	plugins := DiscoverPlugins()
+	if cfg != nil {
+		// Configured paths override PATH discovery
+		for name, path := range cfg.Table(ConfigPlugins) {
+			plugins[name] = path
+		}
+		if p, ok := cfg.List(ConfigPriority); ok && len(p) > 0 {
+			r.priority = p
+		}
+	}
+
+	for name, path := range plugins {
+		if _, builtin := r.reviewers[name]; builtin {
+			continue // Plugins cannot shadow built-ins
+		}
+		r.reviewers[name] = &PluginReviewer{PluginName: name, Path: path}
+		r.plugins = append(r.plugins, name)
+	}
+	sort.Strings(r.plugins)
+
+	return r
+}
+
+// Names returns every reviewer name: built-ins first, then plugins.
+func (r *Registry) Names() []string {
+	return append(append([]string{}, builtinNames...), r.plugins...)
+}
+
+// Priority returns the auto-detection order.
+func (r *Registry) Priority() []string {
+	return r.priority
+}
+
+// Get returns the named reviewer if it is available.
+func (r *Registry) Get(name string) (Reviewer, error) {
+	rev, ok := r.reviewers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown reviewer: %s", name)
+	}
+	if !rev.Available() {
+		return nil, unavailableError(name, rev)
+	}
+	return rev, nil
+}
+
+// Best returns the first available reviewer in priority order.
+// Names in the priority list that are not registered are skipped.
+func (r *Registry) Best() Reviewer {
+	for _, name := range r.priority {
+		if rev, ok := r.reviewers[name]; ok && rev.Available() {
+			return rev
+		}
+	}
+	return &NullReviewer{}
+}
+
+func unavailableError(name string, rev Reviewer) error {
+	switch name {
+	case FlagCouncil:
+		return fmt.Errorf("council not found in PATH")
+	case FlagAI:
+		return secret.ErrNoAPIKey
+	}
+	if p, ok := rev.(*PluginReviewer); ok {
+		return fmt.Errorf("reviewer plugin %s is not executable: %s", name, p.Path)
+	}
+	return fmt.Errorf("reviewer %s is not available", name)
+}
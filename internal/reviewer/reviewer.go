@@ -1,32 +1,33 @@
 package reviewer
 
-import (
-	"errors"
-	"fmt"
-)
+import "craft/internal/usage"
 
 // Reviewer name constants.
 const (
-	NameCouncil = "Council"
-	NameAI      = "AI"
-	NameNone    = "None"
+	NameCouncil   = "Council"
+	NameAI        = "AI"
+	NameHeuristic = "Heuristic"
+	NameNone      = "None"
 
 	// CLI flag values (lowercase).
-	FlagCouncil = "council"
-	FlagAI      = "ai"
-	FlagNone    = "none"
+	FlagCouncil   = "council"
+	FlagAI        = "ai"
+	FlagHeuristic = "heuristic"
+	FlagNone      = "none"
 )
 
 // ReviewRequest contains the context for a review.
 type ReviewRequest struct {
-	Intent string
-	Notes  []string
+	Intent  string   `json:"intent"`
+	Notes   []string `json:"notes"`
+	Context string   `json:"context,omitempty"` // Repository context; see repocontext.Collect
 }
 
 // ReviewResponse contains the review output.
 type ReviewResponse struct {
-	Content  string // The review text
-	Reviewer string // e.g., "AI", "Council", "None"
+	Content  string       `json:"content"`  // The review text
+	Reviewer string       `json:"reviewer"` // e.g., "AI", "Council", "None"
+	Usage    []usage.Call `json:"-"`        // AI calls made, if any
 }
 
 // Reviewer can review workflow intent.
@@ -42,41 +43,23 @@ type Reviewer interface {
 }
 
 // GetBestReviewer returns the highest-priority available reviewer.
-// Priority: Council > AI > Null
+// Priority comes from reviewers.priority in the project config, defaulting
+// to Council > AI > Heuristic > Null.
 func GetBestReviewer() Reviewer {
-	reviewers := []Reviewer{
-		&CouncilReviewer{},
-		&AIReviewer{},
-		&NullReviewer{},
-	}
-
-	for _, r := range reviewers {
-		if r.Available() {
-			return r
-		}
-	}
-
-	return &NullReviewer{} // Should never reach here
+	return defaultRegistry().Best()
 }
 
-// GetReviewer returns a specific reviewer by name.
+// GetReviewer returns a specific reviewer by name, built-in or plugin.
 func GetReviewer(name string) (Reviewer, error) {
-	switch name {
-	case FlagCouncil:
-		r := &CouncilReviewer{}
-		if !r.Available() {
-			return nil, errors.New("council not found in PATH")
-		}
-		return r, nil
-	case FlagAI:
-		r := &AIReviewer{}
-		if !r.Available() {
-			return nil, errors.New("CRAFT_AI_API_KEY not set")
-		}
-		return r, nil
-	case FlagNone:
-		return &NullReviewer{}, nil
-	default:
-		return nil, fmt.Errorf("unknown reviewer: %s", name)
+	return defaultRegistry().Get(name)
+}
+
+// defaultRegistry loads the registry, falling back to built-in defaults if
+// the project config cannot be read.
+func defaultRegistry() *Registry {
+	r, err := LoadRegistry()
+	if err != nil {
+		return NewRegistry(nil)
 	}
+	return r
 }
@@ -5,8 +5,12 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"craft/internal/cassette"
+	"craft/internal/config"
 )
 
 func TestNullReviewer_Name(t *testing.T) {
@@ -46,6 +50,7 @@ func TestAIReviewer_Name(t *testing.T) {
 }
 
 func TestAIReviewer_Available_NoKey(t *testing.T) {
+	isolateUserConfig(t)
 	os.Unsetenv("CRAFT_AI_API_KEY")
 	r := &AIReviewer{}
 	if r.Available() {
@@ -71,6 +76,7 @@ func TestCouncilReviewer_Name(t *testing.T) {
 
 func TestGetBestReviewer_NullFallback(t *testing.T) {
 	// Ensure no AI key is set
+	isolateUserConfig(t)
 	os.Unsetenv("CRAFT_AI_API_KEY")
 	// Council likely not in PATH during tests
 
@@ -99,6 +105,7 @@ func TestGetReviewer_None(t *testing.T) {
 }
 
 func TestGetReviewer_AI_NotAvailable(t *testing.T) {
+	isolateUserConfig(t)
 	os.Unsetenv("CRAFT_AI_API_KEY")
 	_, err := GetReviewer("ai")
 	if err == nil {
@@ -123,7 +130,10 @@ func TestBuildPrompt(t *testing.T) {
 		Intent: "Add rate limiting",
 		Notes:  []string{"Token bucket", "Per-user"},
 	}
-	prompt := buildPrompt(req)
+	prompt, err := buildPrompt(req)
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
 
 	if prompt == "" {
 		t.Error("expected non-empty prompt")
@@ -141,7 +151,10 @@ func TestBuildPrompt_NoNotes(t *testing.T) {
 		Intent: "Test intent",
 		Notes:  nil,
 	}
-	prompt := buildPrompt(req)
+	prompt, err := buildPrompt(req)
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
 
 	if !strings.Contains(prompt, "(none)") {
 		t.Error("prompt should indicate no notes")
@@ -208,3 +221,327 @@ func TestAIReviewer_Review_APIError(t *testing.T) {
 		t.Errorf("expected error to contain 'Invalid API key', got '%s'", err.Error())
 	}
 }
+
+func TestBuildPrompt_WithContext(t *testing.T) {
+	req := ReviewRequest{
+		Intent:  "Add rate limiting",
+		Context: "### go.mod\n```\nmodule example\n```",
+	}
+	prompt, err := buildPrompt(req)
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+
+	if !strings.Contains(prompt, "Repository context:") || !strings.Contains(prompt, "module example") {
+		t.Error("prompt should contain repository context")
+	}
+}
+
+func TestAIReviewer_Review_Usage(t *testing.T) {
+	os.Setenv("CRAFT_AI_API_KEY", "test-key")
+	defer os.Unsetenv("CRAFT_AI_API_KEY")
+
+	mockResp := `{"model":"gpt-4o-mini-2024","choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":120,"completion_tokens":30}}`
+	mock := &mockHTTPClient{
+		Response: &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(mockResp)),
+		},
+	}
+
+	r := &AIReviewer{Client: mock}
+	resp, err := r.Review(ReviewRequest{Intent: "Test intent"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Usage) != 1 {
+		t.Fatalf("expected 1 usage record, got %d", len(resp.Usage))
+	}
+	call := resp.Usage[0]
+	if call.Model != "gpt-4o-mini-2024" || call.Purpose != "review" {
+		t.Errorf("unexpected call metadata: %+v", call)
+	}
+	if call.PromptTokens != 120 || call.CompletionTokens != 30 {
+		t.Errorf("expected 120/30 tokens, got %d/%d", call.PromptTokens, call.CompletionTokens)
+	}
+}
+
+func TestAIReviewer_Review_Replay(t *testing.T) {
+	isolateUserConfig(t)
+	os.Unsetenv("CRAFT_AI_API_KEY")
+
+	path := filepath.Join(t.TempDir(), "review.json")
+	os.Setenv(cassette.EnvRecord, path)
+	rec := &AIReviewer{Client: cassette.Wrap(&mockHTTPClient{
+		Response: &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"choices":[{"message":{"content":"Recorded review"}}]}`)),
+		},
+	})}
+	os.Setenv("CRAFT_AI_API_KEY", "test-key")
+	if _, err := rec.Review(ReviewRequest{Intent: "Replay me"}); err != nil {
+		t.Fatalf("recording failed: %v", err)
+	}
+	os.Unsetenv(cassette.EnvRecord)
+	isolateUserConfig(t)
+	os.Unsetenv("CRAFT_AI_API_KEY")
+
+	os.Setenv(cassette.EnvReplay, path)
+	defer os.Unsetenv(cassette.EnvReplay)
+
+	r := &AIReviewer{}
+	if !r.Available() {
+		t.Error("AIReviewer should be available when replaying")
+	}
+	resp, err := r.Review(ReviewRequest{Intent: "Replay me"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Content != "Recorded review" {
+		t.Errorf("expected 'Recorded review', got '%s'", resp.Content)
+	}
+}
+
+func TestHeuristicReviewer_Available(t *testing.T) {
+	r := &HeuristicReviewer{}
+	if !r.Available() {
+		t.Error("HeuristicReviewer should always be available")
+	}
+	if r.Name() != "Heuristic" {
+		t.Errorf("expected Name() = 'Heuristic', got '%s'", r.Name())
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    ReviewRequest
+		expect []string // substrings expected among findings
+		reject []string // substrings that must not appear
+	}{
+		{
+			name:   "vague verb and noun",
+			req:    ReviewRequest{Intent: "Refactor stuff in the billing module"},
+			expect: []string{`"refactor" is vague`, `"stuff" hides the subject`},
+		},
+		{
+			name:   "compound intent",
+			req:    ReviewRequest{Intent: "Add rate limiting and rewrite the logging pipeline"},
+			expect: []string{`joins work with "and"`},
+		},
+		{
+			name:   "too short",
+			req:    ReviewRequest{Intent: "Caching"},
+			expect: []string{"only 1 word"},
+		},
+		{
+			name:   "too long",
+			req:    ReviewRequest{Intent: strings.Repeat("word ", 45)},
+			expect: []string{"45 words long"},
+		},
+		{
+			name:   "missing criteria and constraints",
+			req:    ReviewRequest{Intent: "Add rate limiting to the public API"},
+			expect: []string{"No success criteria", "No constraints"},
+		},
+		{
+			name: "criteria and constraints in notes",
+			req: ReviewRequest{
+				Intent: "Add rate limiting to the public API",
+				Notes:  []string{"Done when clients get 429 above 100 req/s", "Must not break existing API keys"},
+			},
+			reject: []string{"No success criteria", "No constraints"},
+		},
+		{
+			name:   "digits are not criteria",
+			req:    ReviewRequest{Intent: "Migrate login to OAuth2 on API v3"},
+			expect: []string{"No success criteria"},
+		},
+		{
+			name:   "measurable target",
+			req:    ReviewRequest{Intent: "Cut p99 checkout latency to 200ms"},
+			reject: []string{"No success criteria"},
+		},
+		{
+			name:   "percentage target",
+			req:    ReviewRequest{Intent: "Reduce failed payment retries by 5 %"},
+			reject: []string{"No success criteria"},
+		},
+		{
+			name: "multi-line intent",
+			req: ReviewRequest{
+				Intent: "Add rate limiting to the public API\n\n" + strings.Repeat("Context ", 45) +
+					"\n\n- Done when clients get 429 and a Retry-After header\n- Must not break existing API keys",
+			},
+			reject: []string{"words long", "joins work", "No success criteria", "No constraints"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := strings.Join(Analyze(tt.req), "\n")
+			for _, want := range tt.expect {
+				if !strings.Contains(findings, want) {
+					t.Errorf("findings missing %q, got:\n%s", want, findings)
+				}
+			}
+			for _, unwanted := range tt.reject {
+				if strings.Contains(findings, unwanted) {
+					t.Errorf("findings should not contain %q, got:\n%s", unwanted, findings)
+				}
+			}
+		})
+	}
+}
+
+func TestHeuristicReviewer_Review(t *testing.T) {
+	r := &HeuristicReviewer{}
+	resp, err := r.Review(ReviewRequest{Intent: "Improve things"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if resp.Reviewer != "Heuristic" {
+		t.Errorf("expected Reviewer = 'Heuristic', got '%s'", resp.Reviewer)
+	}
+	if !strings.Contains(resp.Content, `"improve" is vague`) {
+		t.Errorf("expected review to question 'improve', got:\n%s", resp.Content)
+	}
+}
+
+func TestGetBestReviewer_HeuristicBeforeNull(t *testing.T) {
+	isolateUserConfig(t)
+	os.Unsetenv("CRAFT_AI_API_KEY")
+	t.Setenv("PATH", t.TempDir()) // No council
+
+	r := GetBestReviewer()
+	if r.Name() != "Heuristic" {
+		t.Errorf("expected Heuristic reviewer, got '%s'", r.Name())
+	}
+
+	r, err := GetReviewer("heuristic")
+	if err != nil || r.Name() != "Heuristic" {
+		t.Errorf("GetReviewer(heuristic) = %v, %v", r, err)
+	}
+}
+
+// writePlugin creates an executable reviewer plugin script in dir.
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, PluginPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPluginReviewer_Review(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "echo", `input=$(cat)
+case "$input" in
+  *'"intent":"Add rate limiting"'*) echo '{"content":"Plugin saw the intent"}' ;;
+  *) echo '{"content":"wrong input"}' ;;
+esac
+`)
+
+	r := &PluginReviewer{PluginName: "echo", Path: path}
+	if !r.Available() {
+		t.Fatal("plugin should be available")
+	}
+	resp, err := r.Review(ReviewRequest{Intent: "Add rate limiting"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Content != "Plugin saw the intent" {
+		t.Errorf("expected plugin content, got '%s'", resp.Content)
+	}
+	if resp.Reviewer != "echo" {
+		t.Errorf("expected Reviewer = 'echo', got '%s'", resp.Reviewer)
+	}
+}
+
+func TestPluginReviewer_Failures(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"exit", "echo 'boom' >&2\nexit 3\n", "boom"},
+		{"badjson", "echo 'not json'\n", "invalid JSON"},
+		{"empty", "echo '{}'\n", "no content"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &PluginReviewer{PluginName: tt.name, Path: writePlugin(t, dir, tt.name, tt.script)}
+			_, err := r.Review(ReviewRequest{Intent: "x"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRegistry_DiscoversPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "security", "echo '{\"content\":\"ok\"}'\n")
+	writePlugin(t, dir, "ai", "echo '{\"content\":\"shadow\"}'\n")
+	os.WriteFile(filepath.Join(dir, PluginPrefix+"noexec"), []byte("x"), 0644)
+	t.Setenv("PATH", dir)
+
+	r := NewRegistry(nil)
+	names := strings.Join(r.Names(), ",")
+	if names != "council,ai,heuristic,none,security" {
+		t.Errorf("Names() = %s", names)
+	}
+
+	rev, err := r.Get("security")
+	if err != nil {
+		t.Fatalf("Get(security) error = %v", err)
+	}
+	if rev.Name() != "security" {
+		t.Errorf("expected plugin reviewer, got '%s'", rev.Name())
+	}
+
+	if _, err := r.Get("noexec"); err == nil {
+		t.Error("non-executable files should not be registered")
+	}
+}
+
+func TestRegistry_ConfiguredPriority(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "custom", "echo '{\"content\":\"ok\"}'\n")
+	t.Setenv("PATH", t.TempDir())
+	isolateUserConfig(t)
+	os.Unsetenv("CRAFT_AI_API_KEY")
+
+	cfg, err := config.Parse([]byte(`
+[reviewers]
+priority = ["missing", "ai", "linter", "heuristic"]
+
+[reviewers.plugins]
+linter = "` + path + `"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistry(cfg)
+	if got := r.Best().Name(); got != "linter" {
+		t.Errorf("Best() = %s, want linter", got)
+	}
+
+	r = NewRegistry(nil)
+	if got := r.Best().Name(); got != "Heuristic" {
+		t.Errorf("Best() with defaults = %s, want Heuristic", got)
+	}
+}
+
+// isolateUserConfig keeps a developer's own ~/.config/craft out of the test.
+func isolateUserConfig(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Discover finds <prefix><name> executables on PATH and returns their
+// paths by name. Earlier PATH entries win, as with command lookup.
+func Discover(prefix string) map[string]string {
+	found := make(map[string]string)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), prefix)
+			if !ok || name == "" {
+				continue
+			}
+			if _, seen := found[name]; seen {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if IsExecutable(path) {
+				found[name] = path
+			}
+		}
+	}
+	return found
+}
+
+// IsExecutable reports whether path is a regular file anyone may execute.
+func IsExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
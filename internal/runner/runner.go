@@ -0,0 +1,243 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"craft/internal/config"
+)
+
+const (
+	envTimeout = "CRAFT_EXEC_TIMEOUT"
+
+	// Config keys
+	ConfigTimeout   = "exec.timeout"
+	ConfigMaxOutput = "exec.max_output"
+	ConfigPassEnv   = config.KeyPassEnv
+
+	DefaultTimeout   = 2 * time.Minute
+	DefaultMaxOutput = 1 << 20 // 1 MiB
+
+	// waitDelay is how long a process gets to exit after being interrupted
+	// before it is killed.
+	waitDelay = 2 * time.Second
+)
+
+// passEnv lists environment variables external tools receive by default.
+// Anything else, API keys included, must be allowed via exec.pass_env.
+var passEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "TZ", "LANG",
+	"TMPDIR", "TMP", "TEMP", "SYSTEMROOT", "COMSPEC", "PATHEXT",
+}
+
+// passEnvPrefixes are variable families passed through by default.
+var passEnvPrefixes = []string{"LC_", "XDG_"}
+
+// Spec describes an external process to run.
+type Spec struct {
+	Name      string        // Display name used in errors
+	Path      string        // Executable path or name resolved on PATH
+	Args      []string      // Arguments, not including the executable
+	Stdin     []byte        // Sent on stdin; nil for no input
+	Timeout   time.Duration // Zero uses the configured default
+	MaxOutput int           // Bytes kept per stream; zero uses the configured default
+	Env       []string      // Extra KEY=value entries added to the scrubbed environment
+}
+
+// Result holds the captured output of a successful run.
+type Result struct {
+	Stdout []byte
+	Stderr []byte
+}
+
+// ExitError reports a process that ran but exited non-zero.
+type ExitError struct {
+	Name   string
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s failed: %s", e.Name, e.Stderr)
+	}
+	return fmt.Sprintf("%s failed: exit status %d", e.Name, e.Code)
+}
+
+// Settings are the project-wide limits applied when a Spec leaves them unset.
+type Settings struct {
+	Timeout   time.Duration
+	MaxOutput int
+	PassEnv   []string
+}
+
+// LoadSettings reads exec.* from the config; exec.pass_env is only read
+// from the user config. CRAFT_EXEC_TIMEOUT overrides exec.timeout.
+func LoadSettings() (Settings, error) {
+	s := Settings{Timeout: DefaultTimeout, MaxOutput: DefaultMaxOutput}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return s, err
+	}
+
+	timeout, _ := cfg.Get(ConfigTimeout)
+	if v := os.Getenv(envTimeout); v != "" {
+		timeout = v
+	}
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return s, fmt.Errorf("invalid exec timeout %q", timeout)
+		}
+		s.Timeout = d
+	}
+
+	if v, ok := cfg.Get(ConfigMaxOutput); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return s, fmt.Errorf("invalid %s %q", ConfigMaxOutput, v)
+		}
+		s.MaxOutput = n
+	}
+
+	s.PassEnv, _ = cfg.List(ConfigPassEnv)
+	return s, nil
+}
+
+// Run executes spec and waits for it to finish. The process is interrupted
+// (then killed) on timeout or when craft receives Ctrl-C. Output beyond the
+// size limit is discarded; overflowing stdout is an error since the result
+// would be incomplete.
+func Run(ctx context.Context, spec Spec) (Result, error) {
+	settings, err := LoadSettings()
+	if err != nil {
+		return Result{}, err
+	}
+	if spec.Timeout == 0 {
+		spec.Timeout = settings.Timeout
+	}
+	if spec.MaxOutput == 0 {
+		spec.MaxOutput = settings.MaxOutput
+	}
+	if spec.Name == "" {
+		spec.Name = spec.Path
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, spec.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, spec.Path, spec.Args...)
+	cmd.Env = append(ScrubEnv(os.Environ(), settings.PassEnv), spec.Env...)
+	cmd.Cancel = func() error {
+		// Give the tool a chance to clean up before WaitDelay kills it
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = waitDelay
+
+	if spec.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(spec.Stdin)
+	}
+	stdout := &limitedBuffer{limit: spec.MaxOutput}
+	stderr := &limitedBuffer{limit: spec.MaxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	runErr := cmd.Run()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return Result{}, fmt.Errorf("%s timed out after %s", spec.Name, spec.Timeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return Result{}, fmt.Errorf("%s interrupted", spec.Name)
+	}
+
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return Result{}, &ExitError{
+				Name:   spec.Name,
+				Code:   exitErr.ExitCode(),
+				Stderr: strings.TrimSpace(stderr.String()),
+			}
+		}
+		return Result{}, fmt.Errorf("%s failed: %w", spec.Name, runErr)
+	}
+
+	if stdout.truncated {
+		return Result{}, fmt.Errorf("%s output exceeded %d bytes", spec.Name, spec.MaxOutput)
+	}
+
+	return Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}, nil
+}
+
+// ScrubEnv keeps only the default pass-through variables plus any named in extra.
+func ScrubEnv(environ []string, extra []string) []string {
+	allowed := make(map[string]bool)
+	for _, k := range passEnv {
+		allowed[k] = true
+	}
+	for _, k := range extra {
+		allowed[k] = true
+	}
+
+	var out []string
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if allowed[key] || hasAnyPrefix(key, passEnvPrefixes) {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedBuffer keeps the first limit bytes written and drops the rest.
+// The buffer is a named field so io.Copy cannot bypass Write via ReadFrom.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	room := b.limit - b.buf.Len()
+	if len(p) > room {
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
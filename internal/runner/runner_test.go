@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupTest(t *testing.T) func() {
+	t.Helper()
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return func() {
+		os.Chdir(origDir)
+	}
+}
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunStdin(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	path := writeScript(t, "cat")
+	result, err := Run(context.Background(), Spec{Path: path, Stdin: []byte("payload")})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if string(result.Stdout) != "payload" {
+		t.Errorf("Stdout = %q, want payload", result.Stdout)
+	}
+}
+
+func TestRunExitError(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	path := writeScript(t, "echo broken >&2; exit 3")
+	_, err := Run(context.Background(), Spec{Name: "tool", Path: path})
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Run() error = %v, want ExitError", err)
+	}
+	if exitErr.Code != 3 || exitErr.Stderr != "broken" {
+		t.Errorf("ExitError = %+v", exitErr)
+	}
+	if err.Error() != "tool failed: broken" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	path := writeScript(t, "exec sleep 5")
+	start := time.Now()
+	_, err := Run(context.Background(), Spec{Name: "tool", Path: path, Timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Run() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("Run() took %s after timeout", elapsed)
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	path := writeScript(t, "exec sleep 5")
+	_, err := Run(ctx, Spec{Name: "tool", Path: path})
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatalf("Run() error = %v, want interrupted", err)
+	}
+}
+
+func TestRunOutputLimit(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	path := writeScript(t, "printf '0123456789'")
+	_, err := Run(context.Background(), Spec{Name: "tool", Path: path, MaxOutput: 4})
+	if err == nil || !strings.Contains(err.Error(), "exceeded 4 bytes") {
+		t.Fatalf("Run() error = %v, want output limit", err)
+	}
+}
+
+func TestRunScrubsEnvironment(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("CRAFT_AI_API_KEY", "secret")
+	t.Setenv("CRAFT_TEST_ALLOWED", "yes")
+	os.MkdirAll(filepath.Join(home, "craft"), 0755)
+	os.WriteFile(filepath.Join(home, "craft", "config.toml"), []byte("[exec]\npass_env = [\"CRAFT_TEST_ALLOWED\"]\n"), 0600)
+
+	// A cloned repository can't ask for secrets to be passed on
+	os.MkdirAll(".craft", 0755)
+	os.WriteFile(".craft/config.toml", []byte("[exec]\npass_env = [\"CRAFT_AI_API_KEY\"]\n"), 0644)
+
+	path := writeScript(t, `echo "key=$CRAFT_AI_API_KEY allowed=$CRAFT_TEST_ALLOWED extra=$EXTRA"`)
+	result, err := Run(context.Background(), Spec{Path: path, Env: []string{"EXTRA=1"}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := strings.TrimSpace(string(result.Stdout)); got != "key= allowed=yes extra=1" {
+		t.Errorf("Stdout = %q", got)
+	}
+}
+
+func TestScrubEnv(t *testing.T) {
+	env := []string{"PATH=/bin", "OPENAI_API_KEY=x", "LC_ALL=C", "HOME=/home/me", "GITHUB_TOKEN=y"}
+	got := ScrubEnv(env, nil)
+	want := "PATH=/bin LC_ALL=C HOME=/home/me"
+	if strings.Join(got, " ") != want {
+		t.Errorf("ScrubEnv() = %v, want %s", got, want)
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	if s.Timeout != DefaultTimeout || s.MaxOutput != DefaultMaxOutput {
+		t.Errorf("LoadSettings() = %+v, want defaults", s)
+	}
+
+	os.MkdirAll(".craft", 0755)
+	os.WriteFile(".craft/config.toml", []byte("[exec]\ntimeout = \"30s\"\nmax_output = 2048\n"), 0644)
+	s, err = LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	if s.Timeout != 30*time.Second || s.MaxOutput != 2048 {
+		t.Errorf("LoadSettings() = %+v", s)
+	}
+
+	t.Setenv("CRAFT_EXEC_TIMEOUT", "5s")
+	s, _ = LoadSettings()
+	if s.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %s, want 5s from env", s.Timeout)
+	}
+
+	t.Setenv("CRAFT_EXEC_TIMEOUT", "soon")
+	if _, err := LoadSettings(); err == nil {
+		t.Error("LoadSettings() should reject an invalid timeout")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(first, "craft-test-a"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(first, "craft-test-b"), []byte("#!/bin/sh\n"), 0644) // Not executable
+	os.WriteFile(filepath.Join(first, "craft-test-"), []byte("#!/bin/sh\n"), 0755)  // No name
+	os.WriteFile(filepath.Join(second, "craft-test-a"), []byte("#!/bin/sh\n"), 0755)
+	os.Mkdir(filepath.Join(second, "craft-test-c"), 0755)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	got := Discover("craft-test-")
+	if len(got) != 1 || got["a"] != filepath.Join(first, "craft-test-a") {
+		t.Errorf("Discover() = %v, want only a, from the first PATH entry", got)
+	}
+}
@@ -0,0 +1,128 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"craft/internal/config"
+	"craft/internal/runner"
+)
+
+// EnvAPIKey overrides every configured API key source.
+const EnvAPIKey = "CRAFT_AI_API_KEY"
+
+// ErrNoAPIKey is returned when no API key source is configured.
+var ErrNoAPIKey = fmt.Errorf("no API key: set %s, or %s, %s or %s in the user config",
+	EnvAPIKey, config.KeyAIAPIKeyCmd, config.KeyAIAPIKeyFile, config.KeyAIAPIKey)
+
+// Configured reports whether any API key source is configured, without
+// running a command or reading a file.
+func Configured() bool {
+	cfg, err := config.Load()
+	if err != nil {
+		return false
+	}
+	for _, key := range []string{config.KeyAIAPIKey, config.KeyAIAPIKeyCmd, config.KeyAIAPIKeyFile} {
+		if v, ok := cfg.Get(key); ok && v != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey resolves the AI API key from the first source configured:
+// CRAFT_AI_API_KEY, ai.api_key_cmd, ai.api_key_file, then ai.api_key in the
+// user config. Errors never contain the key.
+func APIKey() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+
+	if v, ok := cfg.Lookup(config.KeyAIAPIKey); ok && v.Origin == "env:"+EnvAPIKey && v.Scalar != "" {
+		return v.Scalar, nil
+	}
+	if cmd, ok := cfg.Get(config.KeyAIAPIKeyCmd); ok && cmd != "" {
+		return fromCommand(cmd)
+	}
+	if path, ok := cfg.Get(config.KeyAIAPIKeyFile); ok && path != "" {
+		return fromFile(path)
+	}
+	if key, ok := cfg.Get(config.KeyAIAPIKey); ok && key != "" {
+		return key, nil
+	}
+	return "", ErrNoAPIKey
+}
+
+// fromCommand runs cmd with the shell and uses the first line it prints.
+// The command gets the full environment: password managers such as pass
+// or op need their agent and session variables.
+func fromCommand(cmd string) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	res, err := runner.Run(context.Background(), runner.Spec{
+		Name: config.KeyAIAPIKeyCmd,
+		Path: shell,
+		Args: []string{flag, cmd},
+		Env:  os.Environ(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	key := firstLine(string(res.Stdout))
+	if key == "" {
+		return "", fmt.Errorf("%s printed no key", config.KeyAIAPIKeyCmd)
+	}
+	return key, nil
+}
+
+// fromFile reads the key from path, which must not be readable by other
+// users.
+func fromFile(path string) (string, error) {
+	path = expandHome(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", config.KeyAIAPIKeyFile, path, errors.Unwrap(err))
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("%s %s is accessible by other users (mode %04o); run chmod 600 %s",
+			config.KeyAIAPIKeyFile, path, info.Mode().Perm(), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", config.KeyAIAPIKeyFile, path, errors.Unwrap(err))
+	}
+	key := firstLine(string(data))
+	if key == "" {
+		return "", fmt.Errorf("%s %s is empty", config.KeyAIAPIKeyFile, path)
+	}
+	return key, nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
+
+// expandHome replaces a leading ~/ with the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,105 @@
+package secret
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"craft/internal/config"
+)
+
+// setupTest isolates the test from the user's config and environment.
+func setupTest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "home"))
+	t.Setenv(EnvAPIKey, "")
+	return dir
+}
+
+func writeUserConfig(t *testing.T, content string) {
+	t.Helper()
+	path := config.UserPath()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAPIKeyNotConfigured(t *testing.T) {
+	setupTest(t)
+
+	if Configured() {
+		t.Error("Configured() = true, want false")
+	}
+	if _, err := APIKey(); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("APIKey() error = %v, want ErrNoAPIKey", err)
+	}
+}
+
+func TestAPIKeyFromEnv(t *testing.T) {
+	setupTest(t)
+	writeUserConfig(t, "[ai]\napi_key_cmd = \"echo from-cmd\"\n")
+	t.Setenv(EnvAPIKey, "from-env")
+
+	if key, err := APIKey(); err != nil || key != "from-env" {
+		t.Errorf("APIKey() = %q, %v, want from-env", key, err)
+	}
+}
+
+func TestAPIKeyFromCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	setupTest(t)
+	writeUserConfig(t, "[ai]\napi_key_cmd = \"printf 'from-cmd\\\\nextra'\"\napi_key = \"from-config\"\n")
+
+	if !Configured() {
+		t.Error("Configured() = false, want true")
+	}
+	if key, err := APIKey(); err != nil || key != "from-cmd" {
+		t.Errorf("APIKey() = %q, %v, want from-cmd", key, err)
+	}
+
+	writeUserConfig(t, "[ai]\napi_key_cmd = \"exit 3\"\n")
+	if _, err := APIKey(); err == nil {
+		t.Error("APIKey() should fail when the command fails")
+	}
+}
+
+func TestAPIKeyFromFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits")
+	}
+	dir := setupTest(t)
+	path := filepath.Join(dir, "key")
+	os.WriteFile(path, []byte("sk-file-secret\n"), 0600)
+	writeUserConfig(t, "[ai]\napi_key_file = \""+path+"\"\n")
+
+	if key, err := APIKey(); err != nil || key != "sk-file-secret" {
+		t.Errorf("APIKey() = %q, %v, want sk-file-secret", key, err)
+	}
+
+	os.Chmod(path, 0644)
+	_, err := APIKey()
+	if err == nil || !strings.Contains(err.Error(), "chmod 600") {
+		t.Errorf("APIKey() error = %v, want permission error", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "sk-file-secret") {
+		t.Error("error should not contain the key")
+	}
+}
+
+func TestProjectConfigCannotSetSources(t *testing.T) {
+	setupTest(t)
+	os.MkdirAll(".craft", 0755)
+	os.WriteFile(config.ProjectPath(), []byte("[ai]\napi_key_cmd = \"echo pwned\"\napi_key = \"committed\"\n"), 0644)
+
+	if Configured() {
+		t.Error("Configured() = true, want project sources ignored")
+	}
+}
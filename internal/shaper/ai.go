@@ -5,18 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"regexp"
 	"strings"
 	"time"
 
-	"craft/internal/structure"
+	"craft/internal/cassette"
+	"craft/internal/config"
+	"craft/internal/prompts"
+	"craft/internal/secret"
+	"craft/internal/usage"
 )
 
 const (
-	envAPIKey      = "CRAFT_AI_API_KEY"
-	envModel       = "CRAFT_AI_MODEL"
-	envBaseURL     = "CRAFT_AI_BASE_URL"
 	defaultModel   = "gpt-4o-mini"
 	defaultBaseURL = "https://api.openai.com/v1"
 )
@@ -36,6 +36,10 @@ type HTTPClient interface {
 // AIShaper uses an OpenAI-compatible API to generate structure.
 type AIShaper struct {
 	Client HTTPClient // Optional; uses http.DefaultClient if nil
+
+	// BeforeCards, if set, is called with the pitch call's usage before the
+	// cards are requested; an error stops there, e.g. when over budget.
+	BeforeCards func(spent []usage.Call) error
 }
 
 func (s *AIShaper) Name() string {
@@ -43,182 +47,116 @@ func (s *AIShaper) Name() string {
 }
 
 func (s *AIShaper) Available() bool {
-	return os.Getenv(envAPIKey) != ""
+	return secret.Configured() || cassette.Replaying()
 }
 
 func (s *AIShaper) Shape(req ShapeRequest) (ShapeResult, error) {
-	apiKey := os.Getenv(envAPIKey)
-	if apiKey == "" {
-		return ShapeResult{}, fmt.Errorf("%s not set", envAPIKey)
+	cfg, err := config.Load()
+	if err != nil {
+		return ShapeResult{}, err
 	}
 
-	model := os.Getenv(envModel)
+	var apiKey string
+	if !cassette.Replaying() {
+		apiKey, err = secret.APIKey()
+		if err != nil {
+			return ShapeResult{}, err
+		}
+	}
+
+	model, _ := cfg.Get(config.KeyAIModel)
 	if model == "" {
 		model = defaultModel
 	}
 
-	baseURL := os.Getenv(envBaseURL)
+	baseURL, _ := cfg.Get(config.KeyAIBaseURL)
 	if baseURL == "" {
 		baseURL = defaultBaseURL
 	}
 
 	client := s.Client
 	if client == nil {
-		client = &http.Client{Timeout: 120 * time.Second}
-	}
-
-	// Ensure structure directory exists
-	if err := structure.EnsureStructureDir(); err != nil {
-		return ShapeResult{}, fmt.Errorf("failed to create structure dir: %w", err)
+		client = cassette.Wrap(&http.Client{Timeout: 120 * time.Second})
 	}
 
 	// Generate pitch
-	pitchPrompt := buildPitchPrompt(req)
-	pitchContent, err := callAPI(client, baseURL, apiKey, model, pitchPrompt)
+	pitchPrompt, err := buildPitchPrompt(req)
+	if err != nil {
+		return ShapeResult{}, err
+	}
+	pitchContent, pitchCall, err := callAPI(client, baseURL, apiKey, model, pitchPrompt)
 	if err != nil {
 		return ShapeResult{}, fmt.Errorf("failed to generate pitch: %w", err)
 	}
-
-	// Write pitch file
-	pitchPath := structure.PitchPath()
-	if err := os.WriteFile(pitchPath, []byte(pitchContent), 0644); err != nil {
-		return ShapeResult{}, fmt.Errorf("failed to write pitch: %w", err)
+	pitchCall.Purpose = usage.PurposePitch
+	spent := ShapeResult{Usage: []usage.Call{pitchCall}} // Returned with errors so spend is still recorded
+	if s.BeforeCards != nil {
+		if err := s.BeforeCards(spent.Usage); err != nil {
+			return spent, err
+		}
 	}
 
-	// Generate cards - cleanup pitch on failure
-	cardsPrompt := buildCardsPrompt(req, pitchContent)
-	cardsContent, err := callAPI(client, baseURL, apiKey, model, cardsPrompt)
+	// Generate cards
+	cardsPrompt, err := buildCardsPrompt(req, pitchContent)
 	if err != nil {
-		os.Remove(pitchPath) // Cleanup partial state
-		return ShapeResult{}, fmt.Errorf("failed to generate cards: %w", err)
+		return spent, err
 	}
-
-	// Parse and write card files - cleanup pitch on failure
-	cardPaths, err := parseAndWriteCards(cardsContent)
+	cardsContent, cardsCall, err := callAPI(client, baseURL, apiKey, model, cardsPrompt)
 	if err != nil {
-		os.Remove(pitchPath) // Cleanup partial state
-		return ShapeResult{}, fmt.Errorf("failed to write cards: %w", err)
+		return spent, fmt.Errorf("failed to generate cards: %w", err)
 	}
+	cardsCall.Purpose = usage.PurposeCards
+	spent.Usage = append(spent.Usage, cardsCall)
 
-	return ShapeResult{
-		PitchPath: pitchPath,
-		CardPaths: cardPaths,
-		Shaper:    NameAI,
-	}, nil
-}
-
-func buildPitchPrompt(req ShapeRequest) string {
-	var sb strings.Builder
-	sb.WriteString("Generate a pitch document for this software feature.\n\n")
-	sb.WriteString(fmt.Sprintf("Intent: %s\n\n", req.Intent))
-
-	if len(req.Notes) > 0 {
-		sb.WriteString("Notes:\n")
-		for _, note := range req.Notes {
-			sb.WriteString(fmt.Sprintf("- %s\n", note))
-		}
-		sb.WriteString("\n")
+	// Nothing is written until both calls succeed and the result validates
+	manifest := Manifest{
+		Shaper: NameAI,
+		Pitch:  pitchContent,
+		Cards:  parseCards(cardsContent),
 	}
+	result, err := manifest.Write()
+	if err != nil {
+		return spent, err
+	}
+	result.Usage = spent.Usage
 
-	sb.WriteString(`Format the pitch EXACTLY like this (use markdown):
-
-# Pitch: [Title]
-
-## Problem
-[What problem does this solve? 2-3 sentences]
-
-## Solution
-[How will it be solved? Be specific about approach]
-
-## Scope
-
-### In Scope
-- [Bullet points of what's included]
-
-### Out of Scope
-- [Bullet points of what's NOT included]
-
-## Tasks
-- [ ] [High-level task 1]
-- [ ] [High-level task 2]
-- [ ] [High-level task 3]
-
-Keep it concise. Focus on clarity, not length.`)
-
-	return sb.String()
+	return result, nil
 }
 
-func buildCardsPrompt(req ShapeRequest, pitchContent string) string {
-	var sb strings.Builder
-	sb.WriteString("Break down this pitch into implementation cards.\n\n")
-	sb.WriteString("Pitch:\n")
-	sb.WriteString(pitchContent)
-	sb.WriteString("\n\n")
-
-	sb.WriteString(`Generate 2-5 cards. Each card should be a focused, completable unit of work.
-
-Output format - use this EXACT structure with === as separator:
-
-===CARD===
-# Card: [Descriptive Title]
-
-## Summary
-[One sentence describing what this card accomplishes]
-
-## Tasks
-- [ ] Task 1
-- [ ] Task 2
-- [ ] Task 3
-
-## Acceptance Criteria
-- [How do we know it's done?]
-===END===
-
-Repeat the ===CARD=== ... ===END=== block for each card.
-Number the cards implicitly by order (first card = 01, second = 02, etc).
-Keep cards focused - if a card has more than 5 tasks, split it.`)
-
-	return sb.String()
+func buildPitchPrompt(req ShapeRequest) (string, error) {
+	return prompts.Render(prompts.Pitch, prompts.Data{
+		Intent:  req.Intent,
+		Notes:   req.Notes,
+		Context: req.Context,
+		Repo:    prompts.CurrentRepo(),
+	})
 }
 
-func parseAndWriteCards(content string) ([]string, error) {
-	// Parse cards between ===CARD=== and ===END=== markers
-	matches := cardRegex.FindAllStringSubmatch(content, -1)
+func buildCardsPrompt(req ShapeRequest, pitchContent string) (string, error) {
+	return prompts.Render(prompts.Cards, prompts.Data{
+		Intent:  req.Intent,
+		Notes:   req.Notes,
+		Pitch:   pitchContent,
+		Context: req.Context,
+		Repo:    prompts.CurrentRepo(),
+	})
+}
 
-	var cardPaths []string
-	for i, match := range matches {
+// parseCards extracts cards between ===CARD=== and ===END=== markers.
+func parseCards(content string) []ManifestCard {
+	var cards []ManifestCard
+	for _, match := range cardRegex.FindAllStringSubmatch(content, -1) {
 		if len(match) < 2 {
 			continue
 		}
-
-		cardContent := strings.TrimSpace(match[1])
-
-		// Extract title for filename
-		titleMatch := titleRegex.FindStringSubmatch(cardContent)
-		title := "untitled"
-		if len(titleMatch) >= 2 {
-			title = strings.TrimSpace(titleMatch[1])
-		}
-
-		// Create slug from title
-		slug := strings.ToLower(title)
-		slug = slugRegex.ReplaceAllString(slug, "-")
-		slug = strings.Trim(slug, "-")
-		if len(slug) > 40 {
-			slug = slug[:40]
-		}
-
-		filename := fmt.Sprintf("%02d-%s.md", i+1, slug)
-		cardPath := fmt.Sprintf("%s/%s", structure.CardsDirPath(), filename)
-
-		if err := os.WriteFile(cardPath, []byte(cardContent), 0644); err != nil {
-			return nil, err
-		}
-
-		cardPaths = append(cardPaths, cardPath)
+		cards = append(cards, ManifestCard{Content: strings.TrimSpace(match[1])})
 	}
+	return cards
+}
 
-	return cardPaths, nil
+// parseAndWriteCards parses AI card output and writes the card files.
+func parseAndWriteCards(content string) ([]string, error) {
+	return writeCards(parseCards(content))
 }
 
 type chatRequest struct {
@@ -232,17 +170,24 @@ type chatMessage struct {
 }
 
 type chatResponse struct {
+	Model   string `json:"model"`
 	Choices []struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
 	Error *struct {
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
-func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string, error) {
+// callAPI sends prompt to the chat completions endpoint and returns the
+// reply with the tokens it consumed. Purpose is left for the caller to set.
+func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string, usage.Call, error) {
 	reqBody := chatRequest{
 		Model: model,
 		Messages: []chatMessage{
@@ -252,13 +197,13 @@ func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string,
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", usage.Call{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
 	httpReq, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", usage.Call{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -266,22 +211,34 @@ func callAPI(client HTTPClient, baseURL, apiKey, model, prompt string) (string,
 
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return "", usage.Call{}, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var chatResp chatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return "", usage.Call{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if chatResp.Error != nil {
-		return "", fmt.Errorf("API error: %s", chatResp.Error.Message)
+		return "", usage.Call{}, fmt.Errorf("API error: %s", chatResp.Error.Message)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("no response from AI")
+		return "", usage.Call{}, fmt.Errorf("no response from AI")
+	}
+
+	call := usage.Call{
+		Model: chatResp.Model,
+		At:    time.Now().UTC(),
+	}
+	if call.Model == "" {
+		call.Model = model
+	}
+	if chatResp.Usage != nil {
+		call.PromptTokens = chatResp.Usage.PromptTokens
+		call.CompletionTokens = chatResp.Usage.CompletionTokens
 	}
 
-	return chatResp.Choices[0].Message.Content, nil
+	return chatResp.Choices[0].Message.Content, call, nil
 }
@@ -0,0 +1,146 @@
+package shaper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"craft/internal/structure"
+)
+
+// maxCards bounds how many cards a single shaping run may write.
+const maxCards = 20
+
+// Manifest is the structure a shaper produces. Shapers never write files
+// themselves; craft validates the manifest and writes it.
+type Manifest struct {
+	Shaper string         `json:"shaper,omitempty"`
+	Pitch  string         `json:"pitch"`
+	Cards  []ManifestCard `json:"cards"`
+}
+
+// ManifestCard is one card in a manifest. Title is optional and taken
+// from the "# Card:" heading when empty.
+type ManifestCard struct {
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content"`
+}
+
+// Validate checks the manifest before anything is written.
+func (m Manifest) Validate() error {
+	if strings.TrimSpace(m.Pitch) == "" {
+		return errors.New("manifest has no pitch")
+	}
+	if len(m.Cards) == 0 {
+		return errors.New("manifest has no cards")
+	}
+	if len(m.Cards) > maxCards {
+		return fmt.Errorf("manifest has %d cards, maximum is %d", len(m.Cards), maxCards)
+	}
+	if strings.ContainsRune(m.Pitch, 0) {
+		return errors.New("pitch contains binary data")
+	}
+	for i, c := range m.Cards {
+		if strings.TrimSpace(c.Content) == "" {
+			return fmt.Errorf("card %d has no content", i+1)
+		}
+		if strings.ContainsRune(c.Content, 0) {
+			return fmt.Errorf("card %d contains binary data", i+1)
+		}
+	}
+	return nil
+}
+
+// Write validates the manifest and writes the pitch and numbered card
+// files. On failure, files written so far are removed.
+func (m Manifest) Write() (ShapeResult, error) {
+	if err := m.Validate(); err != nil {
+		return ShapeResult{}, err
+	}
+
+	if err := structure.EnsureStructureDir(); err != nil {
+		return ShapeResult{}, fmt.Errorf("failed to create structure dir: %w", err)
+	}
+
+	// Cards from an earlier generation would mix with the new ones
+	old, err := structure.ListCards()
+	if err != nil {
+		return ShapeResult{}, fmt.Errorf("failed to read cards: %w", err)
+	}
+	for _, path := range old {
+		if err := os.Remove(path); err != nil {
+			return ShapeResult{}, fmt.Errorf("failed to remove old card: %w", err)
+		}
+	}
+
+	pitchPath := structure.PitchPath()
+	if err := os.WriteFile(pitchPath, []byte(ensureNewline(m.Pitch)), 0644); err != nil {
+		return ShapeResult{}, fmt.Errorf("failed to write pitch: %w", err)
+	}
+
+	cardPaths, err := writeCards(m.Cards)
+	if err != nil {
+		os.Remove(pitchPath) // Cleanup partial state
+		return ShapeResult{}, fmt.Errorf("failed to write cards: %w", err)
+	}
+
+	return ShapeResult{
+		PitchPath: pitchPath,
+		CardPaths: cardPaths,
+		Shaper:    m.Shaper,
+	}, nil
+}
+
+// writeCards writes cards as NN-slug.md files in the cards directory.
+func writeCards(cards []ManifestCard) ([]string, error) {
+	var cardPaths []string
+	for i, card := range cards {
+		title := strings.TrimSpace(card.Title)
+		if title == "" {
+			title = cardTitle(card.Content)
+		}
+
+		filename := fmt.Sprintf("%02d-%s.md", i+1, slugify(title))
+		cardPath := filepath.Join(structure.CardsDirPath(), filename)
+
+		if err := os.WriteFile(cardPath, []byte(ensureNewline(card.Content)), 0644); err != nil {
+			for _, p := range cardPaths {
+				os.Remove(p)
+			}
+			return nil, err
+		}
+		cardPaths = append(cardPaths, cardPath)
+	}
+	return cardPaths, nil
+}
+
+// cardTitle extracts the "# Card:" heading, or "untitled".
+func cardTitle(content string) string {
+	if m := titleRegex.FindStringSubmatch(content); len(m) >= 2 {
+		return strings.TrimSpace(m[1])
+	}
+	return "untitled"
+}
+
+// slugify turns a title into a lowercase, dash-separated file name stem.
+func slugify(title string) string {
+	slug := strings.ToLower(title)
+	slug = slugRegex.ReplaceAllString(slug, "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		slug = "untitled"
+	}
+	return slug
+}
+
+func ensureNewline(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,77 @@
+package shaper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"craft/internal/runner"
+)
+
+// PluginPrefix names shaper plugins on PATH: craft-shaper-<name>.
+const PluginPrefix = "craft-shaper-"
+
+// PluginShaper runs an external executable that speaks the shaper
+// protocol: it receives a ShapeRequest as JSON on stdin and writes a
+// Manifest as JSON to stdout. Craft validates and writes the files.
+type PluginShaper struct {
+	PluginName string
+	Path       string
+	Args       []string // Extra arguments, e.g. a subcommand
+}
+
+func (s *PluginShaper) Name() string {
+	return s.PluginName
+}
+
+func (s *PluginShaper) Available() bool {
+	return runner.IsExecutable(s.Path)
+}
+
+func (s *PluginShaper) Shape(req ShapeRequest) (ShapeResult, error) {
+	manifest, err := runPlugin(s.PluginName, s.Path, s.Args, req)
+	if err != nil {
+		return ShapeResult{}, err
+	}
+	if manifest.Shaper == "" {
+		manifest.Shaper = s.PluginName
+	}
+
+	result, err := manifest.Write()
+	if err != nil {
+		return ShapeResult{}, fmt.Errorf("shaper %s: %w", s.PluginName, err)
+	}
+	return result, nil
+}
+
+// runPlugin sends req to the executable and decodes its manifest.
+func runPlugin(name, path string, args []string, req ShapeRequest) (Manifest, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	result, err := runner.Run(context.Background(), runner.Spec{
+		Name:  name,
+		Path:  path,
+		Args:  args,
+		Stdin: input,
+	})
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	var manifest Manifest
+	dec := json.NewDecoder(bytes.NewReader(result.Stdout))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&manifest); err != nil {
+		return Manifest{}, fmt.Errorf("%s returned an invalid manifest: %w", name, err)
+	}
+	return manifest, nil
+}
+
+// DiscoverPlugins finds craft-shaper-<name> executables on PATH.
+func DiscoverPlugins() map[string]string {
+	return runner.Discover(PluginPrefix)
+}
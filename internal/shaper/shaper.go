@@ -1,23 +1,41 @@
 package shaper
 
+import (
+	"fmt"
+	"sort"
+
+	"craft/internal/config"
+	"craft/internal/secret"
+	"craft/internal/usage"
+)
+
 // Shaper name constants.
 const (
 	NameShapeCLI = "ShapeCLI"
 	NameAI       = "AI"
 	NameManual   = "Manual"
+
+	// CLI flag values for `craft shape --generate=<name>`.
+	FlagShapeCLI = "shape-cli"
+	FlagAI       = "ai"
+
+	// ConfigPlugins lists shaper plugins by path in the user config.
+	ConfigPlugins = config.KeyShaperPlugins
 )
 
 // ShapeRequest contains context for structure generation.
 type ShapeRequest struct {
-	Intent string
-	Notes  []string
+	Intent  string   `json:"intent"`
+	Notes   []string `json:"notes"`
+	Context string   `json:"context,omitempty"` // Repository context; see repocontext.Collect
 }
 
 // ShapeResult contains the generated structure.
 type ShapeResult struct {
-	PitchPath string   // Path to generated pitch
-	CardPaths []string // Paths to generated cards
-	Shaper    string   // e.g., "AI", "ShapeCLI", "Manual"
+	PitchPath string       // Path to generated pitch
+	CardPaths []string     // Paths to generated cards
+	Shaper    string       // e.g., "AI", "ShapeCLI", "Manual"
+	Usage     []usage.Call // AI calls made, if any; set even on error
 }
 
 // Shaper can generate project structure from intent.
@@ -48,3 +66,65 @@ func GetBestShaper() Shaper {
 
 	return nil // No shaper available, manual mode
 }
+
+// Names returns every shaper name: built-ins first, then plugins.
+func Names() []string {
+	names := []string{FlagShapeCLI, FlagAI}
+	found, _ := loadPlugins()
+	var plugins []string
+	for name := range found {
+		plugins = append(plugins, name)
+	}
+	sort.Strings(plugins)
+	return append(names, plugins...)
+}
+
+// GetShaper returns a specific shaper by name, built-in or plugin.
+func GetShaper(name string) (Shaper, error) {
+	var s Shaper
+	switch name {
+	case FlagShapeCLI:
+		s = &ShapeCLIShaper{}
+	case FlagAI:
+		s = &AIShaper{}
+	default:
+		plugins, err := loadPlugins()
+		if err != nil {
+			return nil, err
+		}
+		path, ok := plugins[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown shaper: %s", name)
+		}
+		s = &PluginShaper{PluginName: name, Path: path}
+	}
+
+	if !s.Available() {
+		switch name {
+		case FlagShapeCLI:
+			return nil, fmt.Errorf("shape-cli not found in PATH")
+		case FlagAI:
+			return nil, secret.ErrNoAPIKey
+		default:
+			return nil, fmt.Errorf("shaper plugin %s is not executable", name)
+		}
+	}
+	return s, nil
+}
+
+// loadPlugins merges plugins discovered on PATH with those listed under
+// [shapers.plugins] in the user config. Built-in names cannot be shadowed.
+func loadPlugins() (map[string]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := DiscoverPlugins()
+	for name, path := range cfg.Table(ConfigPlugins) {
+		plugins[name] = path
+	}
+	delete(plugins, FlagShapeCLI)
+	delete(plugins, FlagAI)
+	return plugins, nil
+}
@@ -2,6 +2,7 @@ package shaper
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"craft/internal/structure"
+	"craft/internal/usage"
 )
 
 func setupTest(t *testing.T) func() {
@@ -16,6 +18,10 @@ func setupTest(t *testing.T) func() {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
 	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
 	return func() {
 		os.Chdir(origDir)
 	}
@@ -28,14 +34,14 @@ func TestAIShaperAvailable(t *testing.T) {
 	s := &AIShaper{}
 
 	// Not available without API key
-	os.Unsetenv(envAPIKey)
+	os.Unsetenv("CRAFT_AI_API_KEY")
 	if s.Available() {
 		t.Error("Available() = true, want false (no API key)")
 	}
 
 	// Available with API key
-	os.Setenv(envAPIKey, "test-key")
-	defer os.Unsetenv(envAPIKey)
+	os.Setenv("CRAFT_AI_API_KEY", "test-key")
+	defer os.Unsetenv("CRAFT_AI_API_KEY")
 	if !s.Available() {
 		t.Error("Available() = false, want true (API key set)")
 	}
@@ -60,15 +66,15 @@ func TestGetBestShaper(t *testing.T) {
 	defer cleanup()
 
 	// No shapers available
-	os.Unsetenv(envAPIKey)
+	os.Unsetenv("CRAFT_AI_API_KEY")
 	s := GetBestShaper()
 	if s != nil {
 		t.Errorf("GetBestShaper() = %v, want nil (no shapers available)", s)
 	}
 
 	// AI available
-	os.Setenv(envAPIKey, "test-key")
-	defer os.Unsetenv(envAPIKey)
+	os.Setenv("CRAFT_AI_API_KEY", "test-key")
+	defer os.Unsetenv("CRAFT_AI_API_KEY")
 	s = GetBestShaper()
 	if s == nil || s.Name() != NameAI {
 		t.Errorf("GetBestShaper() = %v, want AI shaper", s)
@@ -89,8 +95,8 @@ func TestAIShaperShape(t *testing.T) {
 	cleanup := setupTest(t)
 	defer cleanup()
 
-	os.Setenv(envAPIKey, "test-key")
-	defer os.Unsetenv(envAPIKey)
+	os.Setenv("CRAFT_AI_API_KEY", "test-key")
+	defer os.Unsetenv("CRAFT_AI_API_KEY")
 
 	// Mock response for pitch
 	pitchResponse := `# Pitch: Test Feature
@@ -195,6 +201,40 @@ Second card summary.
 	_ = mockClient // silence unused warning
 }
 
+func TestAIShaperStopsBeforeCards(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	t.Setenv("CRAFT_AI_API_KEY", "test-key")
+
+	callCount := 0
+	s := &AIShaper{
+		Client: &MockSequentialClient{
+			Responses: []*http.Response{{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"model":"gpt-4o-mini-2024-07-18","choices":[{"message":{"content":"# Pitch"}}],"usage":{"prompt_tokens":100,"completion_tokens":10}}`)),
+			}},
+			CallCount: &callCount,
+		},
+		BeforeCards: func(spent []usage.Call) error {
+			if len(spent) != 1 || spent[0].Purpose != usage.PurposePitch {
+				t.Errorf("BeforeCards() spent = %+v, want the pitch call", spent)
+			}
+			return errors.New("over budget")
+		},
+	}
+
+	result, err := s.Shape(ShapeRequest{Intent: "Test feature"})
+	if err == nil || err.Error() != "over budget" {
+		t.Errorf("Shape() error = %v, want the BeforeCards error", err)
+	}
+	if callCount != 1 || len(result.Usage) != 1 {
+		t.Errorf("calls = %d, usage = %+v, want only the pitch call made and recorded", callCount, result.Usage)
+	}
+	if structure.HasPitch() {
+		t.Error("nothing should be written when shaping stops")
+	}
+}
+
 // MockSequentialClient returns different responses for sequential calls
 type MockSequentialClient struct {
 	Responses []*http.Response
@@ -296,7 +336,10 @@ func TestBuildPitchPrompt(t *testing.T) {
 		Notes:  []string{"Token bucket", "Per-user limits"},
 	}
 
-	prompt := buildPitchPrompt(req)
+	prompt, err := buildPitchPrompt(req)
+	if err != nil {
+		t.Fatalf("buildPitchPrompt() error = %v", err)
+	}
 
 	if !bytes.Contains([]byte(prompt), []byte("Add rate limiting")) {
 		t.Error("Prompt should contain intent")
@@ -313,7 +356,10 @@ func TestBuildCardsPrompt(t *testing.T) {
 	}
 	pitchContent := "# Pitch: Test\n\n## Problem\nTest problem"
 
-	prompt := buildCardsPrompt(req, pitchContent)
+	prompt, err := buildCardsPrompt(req, pitchContent)
+	if err != nil {
+		t.Fatalf("buildCardsPrompt() error = %v", err)
+	}
 
 	if !bytes.Contains([]byte(prompt), []byte("Test problem")) {
 		t.Error("Prompt should contain pitch content")
@@ -323,3 +369,192 @@ func TestBuildCardsPrompt(t *testing.T) {
 		t.Error("Prompt should describe card format")
 	}
 }
+
+func TestManifestValidate(t *testing.T) {
+	card := ManifestCard{Content: "# Card: One"}
+	tests := []struct {
+		name     string
+		manifest Manifest
+		wantErr  bool
+	}{
+		{"valid", Manifest{Pitch: "# Pitch: X", Cards: []ManifestCard{card}}, false},
+		{"no pitch", Manifest{Pitch: "  ", Cards: []ManifestCard{card}}, true},
+		{"no cards", Manifest{Pitch: "# Pitch: X"}, true},
+		{"empty card", Manifest{Pitch: "# Pitch: X", Cards: []ManifestCard{{Content: "\n"}}}, true},
+		{"binary", Manifest{Pitch: "# Pitch: X\x00", Cards: []ManifestCard{card}}, true},
+		{"too many", Manifest{Pitch: "# Pitch: X", Cards: make([]ManifestCard, maxCards+1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.manifest.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManifestWrite(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	m := Manifest{
+		Shaper: "test",
+		Pitch:  "# Pitch: Rate limiting",
+		Cards: []ManifestCard{
+			{Content: "# Card: Token Bucket\n\n## Tasks\n- [ ] Build it"},
+			{Title: "Wire Middleware", Content: "Untitled body"},
+		},
+	}
+
+	result, err := m.Write()
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := []string{
+		filepath.Join(structure.CardsDirPath(), "01-token-bucket.md"),
+		filepath.Join(structure.CardsDirPath(), "02-wire-middleware.md"),
+	}
+	if len(result.CardPaths) != 2 || result.CardPaths[0] != want[0] || result.CardPaths[1] != want[1] {
+		t.Errorf("CardPaths = %v, want %v", result.CardPaths, want)
+	}
+	if result.Shaper != "test" {
+		t.Errorf("Shaper = %q, want test", result.Shaper)
+	}
+	if !structure.HasPitch() {
+		t.Error("Pitch file should exist")
+	}
+}
+
+// writeExecutable creates an executable shell script at dir/name.
+func writeExecutable(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const pluginManifest = `{"pitch":"# Pitch: From plugin","cards":[{"content":"# Card: Plugin Card"}]}`
+
+func TestManifestWriteReplacesCards(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	first := Manifest{Pitch: "# Pitch", Cards: []ManifestCard{
+		{Content: "# Card: One"}, {Content: "# Card: Two"}, {Content: "# Card: Three"},
+	}}
+	if _, err := first.Write(); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	second := Manifest{Pitch: "# Pitch", Cards: []ManifestCard{{Content: "# Card: Only"}}}
+	if _, err := second.Write(); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	cards, _ := structure.ListCards()
+	if len(cards) != 1 || filepath.Base(cards[0]) != "01-only.md" {
+		t.Errorf("cards = %v, want only 01-only.md", cards)
+	}
+}
+
+func TestPluginShaper(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	writeExecutable(t, dir, PluginPrefix+"team", `input=$(cat)
+case "$input" in
+  *'"intent":"Add rate limiting"'*) echo '`+pluginManifest+`' ;;
+  *) echo 'unexpected input' >&2; exit 1 ;;
+esac
+`)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	s, err := GetShaper("team")
+	if err != nil {
+		t.Fatalf("GetShaper(team) error = %v", err)
+	}
+
+	result, err := s.Shape(ShapeRequest{Intent: "Add rate limiting"})
+	if err != nil {
+		t.Fatalf("Shape() error = %v", err)
+	}
+	if result.Shaper != "team" {
+		t.Errorf("Shaper = %q, want team", result.Shaper)
+	}
+	if len(result.CardPaths) != 1 || filepath.Base(result.CardPaths[0]) != "01-plugin-card.md" {
+		t.Errorf("CardPaths = %v", result.CardPaths)
+	}
+
+	names := Names()
+	if len(names) != 3 || names[2] != "team" {
+		t.Errorf("Names() = %v, want built-ins then team", names)
+	}
+}
+
+func TestPluginShaperInvalidManifest(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	tests := map[string]string{
+		"nopitch": `echo '{"cards":[{"content":"x"}]}'`,
+		"garbage": `echo 'Created .craft/pitch.md'`,
+		"unknown": `echo '{"pitch":"x","cards":[{"content":"x"}],"extra":1}'`,
+		"fails":   `echo 'boom' >&2; exit 2`,
+	}
+
+	for name, script := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &PluginShaper{PluginName: name, Path: writeExecutable(t, dir, name, script+"\n")}
+			if _, err := s.Shape(ShapeRequest{Intent: "x"}); err == nil {
+				t.Error("Shape() should fail")
+			}
+			if structure.HasPitch() {
+				t.Error("nothing should be written for a failed shaper")
+			}
+		})
+	}
+}
+
+func TestShapeCLIShaperProtocol(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	writeExecutable(t, dir, "shape", `if [ "$1 $2" != "generate --format=json" ]; then echo "bad args: $*" >&2; exit 1; fi
+cat >/dev/null
+echo '`+pluginManifest+`'
+`)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	s := &ShapeCLIShaper{}
+	if !s.Available() {
+		t.Fatal("ShapeCLIShaper should be available")
+	}
+	result, err := s.Shape(ShapeRequest{Intent: "x"})
+	if err != nil {
+		t.Fatalf("Shape() error = %v", err)
+	}
+	if result.Shaper != NameShapeCLI || result.PitchPath != structure.PitchPath() {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestGetShaperUnknown(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := GetShaper("nope"); err == nil {
+		t.Error("GetShaper(nope) should return error")
+	}
+	if _, err := GetShaper(FlagShapeCLI); err == nil {
+		t.Error("GetShaper(shape-cli) should fail when shape is not installed")
+	}
+}
@@ -0,0 +1,380 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"craft/internal/state"
+	"craft/internal/workflow"
+)
+
+// Sources a report can be built from.
+const (
+	SourceArchive = "archive"
+	SourceGit     = "git"
+	SourceCurrent = "current"
+)
+
+// Outcome is how a workflow ended, if it has.
+type Outcome string
+
+const (
+	Shipped   Outcome = "shipped"
+	Abandoned Outcome = "abandoned"
+	Active    Outcome = "active"
+)
+
+// phaseOrder lists the phases reported, in workflow order.
+var phaseOrder = []state.State{state.Thinking, state.Shaping, state.Building}
+
+// Record summarizes a single workflow.
+type Record struct {
+	Intent         string
+	StartedAt      time.Time
+	Outcome        Outcome
+	Phases         map[state.State]time.Duration
+	Rejects        int
+	Revises        int
+	SkippedShaping bool
+	TimeToShip     time.Duration // Zero unless shipped
+}
+
+// FromWorkflow builds a record. Finished workflows are measured up to their
+// last history entry; active ones up to now. An unshipped workflow counts
+// as abandoned once it has been archived.
+func FromWorkflow(w *workflow.Workflow, archived bool, now time.Time) Record {
+	r := Record{
+		Intent:    w.Intent,
+		StartedAt: w.StartedAt,
+		Phases:    make(map[state.State]time.Duration),
+	}
+
+	switch {
+	case w.State == state.Shipped:
+		r.Outcome = Shipped
+	case archived:
+		r.Outcome = Abandoned
+	default:
+		r.Outcome = Active
+	}
+
+	end := now
+	if r.Outcome != Active && len(w.History) > 0 {
+		end = w.History[len(w.History)-1].At
+	}
+	for _, p := range w.Phases(end) {
+		r.Phases[p.State] = p.Duration
+	}
+
+	// Undo keeps history and appends "Undid <action>". Undos pop a stack,
+	// so each one reverses the latest reject or revise still standing.
+	var rejects, revises int
+	shaped := false
+	for _, h := range w.History {
+		switch {
+		case strings.HasPrefix(h.Note, "Rejected: "):
+			rejects++
+		case strings.HasPrefix(h.Note, "Revised: "):
+			revises++
+		case h.Note == "Undid reject" && rejects > 0:
+			rejects--
+		case h.Note == "Undid revise" && revises > 0:
+			revises--
+		}
+		switch state.State(h.State) {
+		case state.Shaping:
+			shaped = true
+		case state.Building:
+			r.SkippedShaping = !shaped
+		case state.Shipped:
+			r.TimeToShip = h.At.Sub(w.StartedAt)
+		}
+	}
+
+	r.Rejects, r.Revises = rejects, revises
+
+	// Revisions made before history recorded them are only in the notes
+	if r.Revises == 0 {
+		for _, n := range w.Notes {
+			if strings.HasPrefix(n.Text, "[revise] ") {
+				r.Revises++
+			}
+		}
+	}
+
+	return r
+}
+
+// Load gathers records from the archive plus the current workflow. With an
+// empty archive it falls back to the git history of the workflow file.
+func Load(now time.Time) ([]Record, string, error) {
+	current, _ := workflow.Load()
+
+	archived, err := workflow.LoadArchive()
+	if err != nil {
+		return nil, "", err
+	}
+
+	source := SourceArchive
+	if len(archived) == 0 {
+		archived, err = loadGitHistory(current)
+		if err != nil {
+			return nil, "", err
+		}
+		source = SourceGit
+		if len(archived) == 0 {
+			source = SourceCurrent
+		}
+	}
+
+	var records []Record
+	for _, w := range archived {
+		records = append(records, FromWorkflow(w, true, now))
+	}
+	if current != nil {
+		records = append(records, FromWorkflow(current, false, now))
+	}
+	return records, source, nil
+}
+
+// loadGitHistory reads past versions of the workflow file from git and
+// keeps the last version of each workflow, identified by its start time.
+// The current workflow is excluded; its file on disk is more recent.
+func loadGitHistory(current *workflow.Workflow) ([]*workflow.Workflow, error) {
+	top, path, ok := repoPath(workflow.Path())
+	if !ok {
+		// Not a git repository, or git isn't installed
+		return nil, nil
+	}
+	out, err := exec.Command("git", "-C", top, "log", "--format=%H", "--", path).Output()
+	if err != nil {
+		return nil, nil
+	}
+
+	seen := make(map[time.Time]bool)
+	if current != nil {
+		seen[current.StartedAt] = true
+	}
+
+	// git log lists newest first, so the first version of each workflow
+	// is its final state
+	var workflows []*workflow.Workflow
+	for _, commit := range strings.Fields(string(out)) {
+		data, err := exec.Command("git", "-C", top, "show", commit+":"+path).Output()
+		if err != nil {
+			continue
+		}
+		w, err := workflow.Parse(data)
+		if err != nil || seen[w.StartedAt] {
+			continue
+		}
+		seen[w.StartedAt] = true
+		workflows = append(workflows, w)
+	}
+
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].StartedAt.Before(workflows[j].StartedAt)
+	})
+	return workflows, nil
+}
+
+// repoPath returns the root of the git repository holding path, and path
+// relative to it with forward slashes, as `git show <commit>:<path>` needs
+// whatever the working directory or --dir.
+func repoPath(path string) (top, rel string, ok bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", false
+	}
+	// git reports the root with symlinks resolved
+	dir, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return "", "", false
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", "", false
+	}
+	top = strings.TrimSpace(string(out))
+	rel, err = filepath.Rel(top, filepath.Join(dir, filepath.Base(abs)))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", "", false
+	}
+	return top, filepath.ToSlash(rel), true
+}
+
+// PhaseStat is the cycle time of one phase across workflows that reached it.
+type PhaseStat struct {
+	Phase     string  `json:"phase"`
+	Workflows int     `json:"workflows"`
+	Median    float64 `json:"median_seconds"`
+	Mean      float64 `json:"mean_seconds"`
+}
+
+// Report aggregates records.
+type Report struct {
+	Source           string      `json:"source"`
+	Workflows        int         `json:"workflows"`
+	Shipped          int         `json:"shipped"`
+	Abandoned        int         `json:"abandoned"`
+	Active           int         `json:"active"`
+	AbandonmentRate  float64     `json:"abandonment_rate"` // Abandoned over finished
+	SkippedShaping   int         `json:"skipped_shaping"`
+	Rejects          int         `json:"rejects"`
+	Revises          int         `json:"revises"`
+	MedianTimeToShip float64     `json:"median_time_to_ship_seconds"`
+	Phases           []PhaseStat `json:"phases"`
+}
+
+// Compute aggregates records into a report.
+func Compute(records []Record, source string) Report {
+	rep := Report{Source: source, Workflows: len(records), Phases: []PhaseStat{}}
+
+	var toShip []time.Duration
+	byPhase := make(map[state.State][]time.Duration)
+	for _, r := range records {
+		switch r.Outcome {
+		case Shipped:
+			rep.Shipped++
+			toShip = append(toShip, r.TimeToShip)
+		case Abandoned:
+			rep.Abandoned++
+		case Active:
+			rep.Active++
+		}
+		if r.SkippedShaping {
+			rep.SkippedShaping++
+		}
+		rep.Rejects += r.Rejects
+		rep.Revises += r.Revises
+		for s, d := range r.Phases {
+			byPhase[s] = append(byPhase[s], d)
+		}
+	}
+
+	if finished := rep.Shipped + rep.Abandoned; finished > 0 {
+		rep.AbandonmentRate = float64(rep.Abandoned) / float64(finished)
+	}
+	rep.MedianTimeToShip = median(toShip).Seconds()
+
+	for _, s := range phaseOrder {
+		durations := byPhase[s]
+		if len(durations) == 0 {
+			continue
+		}
+		rep.Phases = append(rep.Phases, PhaseStat{
+			Phase:     string(s),
+			Workflows: len(durations),
+			Median:    median(durations).Seconds(),
+			Mean:      mean(durations).Seconds(),
+		})
+	}
+	return rep
+}
+
+// WriteTable writes the report for humans.
+func (rep Report) WriteTable(w io.Writer) {
+	fmt.Fprintf(w, "Workflows:        %d (%d shipped, %d abandoned, %d active) from %s\n",
+		rep.Workflows, rep.Shipped, rep.Abandoned, rep.Active, rep.Source)
+	fmt.Fprintf(w, "Abandonment rate: %.0f%%\n", rep.AbandonmentRate*100)
+	toShip := "-"
+	if rep.Shipped > 0 {
+		toShip = formatSeconds(rep.MedianTimeToShip)
+	}
+	fmt.Fprintf(w, "Median to ship:   %s\n", toShip)
+	fmt.Fprintf(w, "Skipped shaping:  %d\n", rep.SkippedShaping)
+	fmt.Fprintf(w, "Rejects:          %d\n", rep.Rejects)
+	fmt.Fprintf(w, "Revises:          %d\n", rep.Revises)
+
+	if len(rep.Phases) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-10s %9s %9s %9s\n", "Phase", "Workflows", "Median", "Mean")
+	for _, p := range rep.Phases {
+		fmt.Fprintf(w, "%-10s %9d %9s %9s\n", p.Phase, p.Workflows, formatSeconds(p.Median), formatSeconds(p.Mean))
+	}
+}
+
+// WriteOpenMetrics writes the report in the OpenMetrics text format.
+func (rep Report) WriteOpenMetrics(w io.Writer) {
+	fmt.Fprintln(w, "# HELP craft_workflows Workflows by outcome.")
+	fmt.Fprintln(w, "# TYPE craft_workflows gauge")
+	fmt.Fprintf(w, "craft_workflows{outcome=\"shipped\"} %d\n", rep.Shipped)
+	fmt.Fprintf(w, "craft_workflows{outcome=\"abandoned\"} %d\n", rep.Abandoned)
+	fmt.Fprintf(w, "craft_workflows{outcome=\"active\"} %d\n", rep.Active)
+
+	fmt.Fprintln(w, "# HELP craft_abandonment_ratio Abandoned workflows over finished workflows.")
+	fmt.Fprintln(w, "# TYPE craft_abandonment_ratio gauge")
+	fmt.Fprintf(w, "craft_abandonment_ratio %g\n", rep.AbandonmentRate)
+
+	fmt.Fprintln(w, "# HELP craft_skipped_shaping Workflows that skipped shaping.")
+	fmt.Fprintln(w, "# TYPE craft_skipped_shaping gauge")
+	fmt.Fprintf(w, "craft_skipped_shaping %d\n", rep.SkippedShaping)
+
+	fmt.Fprintln(w, "# HELP craft_concerns Concerns raised by kind.")
+	fmt.Fprintln(w, "# TYPE craft_concerns gauge")
+	fmt.Fprintf(w, "craft_concerns{kind=\"reject\"} %d\n", rep.Rejects)
+	fmt.Fprintf(w, "craft_concerns{kind=\"revise\"} %d\n", rep.Revises)
+
+	fmt.Fprintln(w, "# HELP craft_time_to_ship_median_seconds Median time from start to ship.")
+	fmt.Fprintln(w, "# TYPE craft_time_to_ship_median_seconds gauge")
+	fmt.Fprintln(w, "# UNIT craft_time_to_ship_median_seconds seconds")
+	fmt.Fprintf(w, "craft_time_to_ship_median_seconds %g\n", rep.MedianTimeToShip)
+
+	fmt.Fprintln(w, "# HELP craft_phase_median_seconds Median time spent in each phase.")
+	fmt.Fprintln(w, "# TYPE craft_phase_median_seconds gauge")
+	fmt.Fprintln(w, "# UNIT craft_phase_median_seconds seconds")
+	for _, p := range rep.Phases {
+		fmt.Fprintf(w, "craft_phase_median_seconds{phase=%q} %g\n", p.Phase, p.Median)
+	}
+
+	fmt.Fprintln(w, "# HELP craft_phase_mean_seconds Mean time spent in each phase.")
+	fmt.Fprintln(w, "# TYPE craft_phase_mean_seconds gauge")
+	fmt.Fprintln(w, "# UNIT craft_phase_mean_seconds seconds")
+	for _, p := range rep.Phases {
+		fmt.Fprintf(w, "craft_phase_mean_seconds{phase=%q} %g\n", p.Phase, p.Mean)
+	}
+
+	fmt.Fprintln(w, "# EOF")
+}
+
+func median(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func mean(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range ds {
+		total += d
+	}
+	return total / time.Duration(len(ds))
+}
+
+// formatSeconds renders a duration in seconds compactly, e.g. "2d4h".
+func formatSeconds(s float64) string {
+	d := time.Duration(s * float64(time.Second))
+	if d >= 24*time.Hour {
+		d = d.Round(time.Hour)
+	} else {
+		d = d.Round(time.Minute)
+	}
+	return workflow.FormatDuration(d)
+}
@@ -0,0 +1,265 @@
+package stats
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"craft/internal/state"
+	"craft/internal/workflow"
+)
+
+func setupTest(t *testing.T) func() {
+	t.Helper()
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return func() {
+		os.Chdir(origDir)
+	}
+}
+
+var start = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func shippedWorkflow() *workflow.Workflow {
+	return &workflow.Workflow{
+		State:     state.Shipped,
+		StartedAt: start,
+		Intent:    "Shipped work",
+		History: []workflow.HistoryEntry{
+			{State: "thinking", At: start},
+			{State: "thinking", At: start.Add(1 * time.Hour), Note: "Rejected: too vague"},
+			{State: "shaping", At: start.Add(2 * time.Hour)},
+			{State: "shaping", At: start.Add(3 * time.Hour), Note: "Revised: split card"},
+			{State: "building", At: start.Add(4 * time.Hour)},
+			{State: "shipped", At: start.Add(28 * time.Hour)},
+		},
+	}
+}
+
+func abandonedWorkflow() *workflow.Workflow {
+	return &workflow.Workflow{
+		State:     state.Building,
+		StartedAt: start.Add(48 * time.Hour),
+		Intent:    "Abandoned work",
+		History: []workflow.HistoryEntry{
+			{State: "thinking", At: start.Add(48 * time.Hour)},
+			{State: "building", At: start.Add(50 * time.Hour)},
+			{State: "building", At: start.Add(60 * time.Hour), Note: workflow.NoteAbandoned},
+		},
+	}
+}
+
+func TestFromWorkflow(t *testing.T) {
+	now := start.Add(1000 * time.Hour)
+
+	r := FromWorkflow(shippedWorkflow(), true, now)
+	if r.Outcome != Shipped {
+		t.Errorf("Outcome = %s, want shipped", r.Outcome)
+	}
+	if r.Rejects != 1 || r.Revises != 1 {
+		t.Errorf("Rejects, Revises = %d, %d, want 1, 1", r.Rejects, r.Revises)
+	}
+	if r.SkippedShaping {
+		t.Error("SkippedShaping = true, want false")
+	}
+	if r.TimeToShip != 28*time.Hour {
+		t.Errorf("TimeToShip = %s, want 28h", r.TimeToShip)
+	}
+	if r.Phases[state.Building] != 24*time.Hour {
+		t.Errorf("building = %s, want 24h", r.Phases[state.Building])
+	}
+
+	r = FromWorkflow(abandonedWorkflow(), true, now)
+	if r.Outcome != Abandoned {
+		t.Errorf("Outcome = %s, want abandoned", r.Outcome)
+	}
+	if !r.SkippedShaping {
+		t.Error("SkippedShaping = false, want true")
+	}
+	if r.Phases[state.Building] != 10*time.Hour {
+		t.Errorf("building = %s, want 10h (until archived)", r.Phases[state.Building])
+	}
+
+	r = FromWorkflow(abandonedWorkflow(), false, now)
+	if r.Outcome != Active {
+		t.Errorf("Outcome = %s, want active", r.Outcome)
+	}
+}
+
+func TestFromWorkflowLegacyRevises(t *testing.T) {
+	w := shippedWorkflow()
+	w.History = w.History[:3]
+	w.Notes = []workflow.Note{{Text: "[revise] one"}, {Text: "plain"}, {Text: "[revise] two"}}
+
+	r := FromWorkflow(w, true, start)
+	if r.Revises != 2 {
+		t.Errorf("Revises = %d, want 2 from notes", r.Revises)
+	}
+}
+
+func TestFromWorkflowSkipsUndone(t *testing.T) {
+	w := shippedWorkflow()
+	w.History = []workflow.HistoryEntry{
+		{State: "thinking", At: start},
+		{State: "thinking", At: start.Add(1 * time.Hour), Note: "Rejected: too vague"},
+		{State: "thinking", At: start.Add(2 * time.Hour), Note: "Rejected: by mistake"},
+		{State: "thinking", At: start.Add(3 * time.Hour), Note: "Undid reject"},
+		{State: "shaping", At: start.Add(4 * time.Hour)},
+		{State: "shaping", At: start.Add(5 * time.Hour), Note: "Revised: split card"},
+		{State: "shaping", At: start.Add(6 * time.Hour), Note: "Undid revise"},
+		{State: "shaping", At: start.Add(7 * time.Hour), Note: "Undid accept"},
+	}
+
+	r := FromWorkflow(w, false, start.Add(8*time.Hour))
+	if r.Rejects != 1 || r.Revises != 0 {
+		t.Errorf("Rejects, Revises = %d, %d, want 1, 0 after undos", r.Rejects, r.Revises)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	now := start.Add(1000 * time.Hour)
+	records := []Record{
+		FromWorkflow(shippedWorkflow(), true, now),
+		FromWorkflow(abandonedWorkflow(), true, now),
+	}
+
+	rep := Compute(records, SourceArchive)
+	if rep.Workflows != 2 || rep.Shipped != 1 || rep.Abandoned != 1 {
+		t.Errorf("Compute() = %+v", rep)
+	}
+	if rep.AbandonmentRate != 0.5 {
+		t.Errorf("AbandonmentRate = %v, want 0.5", rep.AbandonmentRate)
+	}
+	if rep.MedianTimeToShip != (28 * time.Hour).Seconds() {
+		t.Errorf("MedianTimeToShip = %v, want 28h", rep.MedianTimeToShip)
+	}
+	if rep.SkippedShaping != 1 {
+		t.Errorf("SkippedShaping = %d, want 1", rep.SkippedShaping)
+	}
+
+	if len(rep.Phases) != 3 || rep.Phases[2].Phase != "building" {
+		t.Fatalf("Phases = %+v", rep.Phases)
+	}
+	building := rep.Phases[2]
+	if building.Median != (17 * time.Hour).Seconds() {
+		t.Errorf("building median = %v, want 17h", building.Median)
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	now := start.Add(1000 * time.Hour)
+	rep := Compute([]Record{FromWorkflow(shippedWorkflow(), true, now)}, SourceArchive)
+
+	var buf bytes.Buffer
+	rep.WriteOpenMetrics(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		`craft_workflows{outcome="shipped"} 1`,
+		`craft_concerns{kind="reject"} 1`,
+		`craft_phase_median_seconds{phase="building"} 86400`,
+		"craft_time_to_ship_median_seconds 100800",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Error("output should end with # EOF")
+	}
+}
+
+func TestLoadFromArchive(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	w := workflow.New("First")
+	w.Save()
+	if _, err := workflow.Archive(); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	workflow.New("Second").Save()
+
+	records, source, err := Load(time.Now())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if source != SourceArchive {
+		t.Errorf("source = %s, want archive", source)
+	}
+	if len(records) != 2 || records[0].Outcome != Abandoned || records[1].Outcome != Active {
+		t.Errorf("records = %+v", records)
+	}
+}
+
+func TestLoadFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+
+	// A shipped workflow, committed in two versions
+	w := shippedWorkflow()
+	w.State = state.Building
+	w.History = w.History[:5]
+	w.Save()
+	git("add", ".craft")
+	git("commit", "-q", "-m", "building")
+	w.Transition(state.Shipped)
+	w.Save()
+	git("commit", "-q", "-am", "shipped")
+
+	// Replaced by a new workflow
+	workflow.New("Next").Save()
+
+	records, source, err := Load(time.Now())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if source != SourceGit {
+		t.Errorf("source = %s, want git", source)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want 2", records)
+	}
+	if records[0].Outcome != Shipped || records[0].Rejects != 1 {
+		t.Errorf("records[0] = %+v, want shipped with 1 reject", records[0])
+	}
+	if records[1].Outcome != Active {
+		t.Errorf("records[1].Outcome = %s, want active", records[1].Outcome)
+	}
+
+	// The same history through an absolute --dir, from another directory
+	root, _ := os.Getwd()
+	os.Mkdir("sub", 0755)
+	os.Chdir("sub")
+	t.Setenv(workflow.EnvDir, root)
+
+	records, source, err = Load(time.Now())
+	if err != nil {
+		t.Fatalf("Load() with --dir error = %v", err)
+	}
+	if source != SourceGit || len(records) != 2 {
+		t.Errorf("Load() with --dir = %s %+v, want 2 records from git", source, records)
+	}
+}
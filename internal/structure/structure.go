@@ -13,17 +13,14 @@ const (
 	CardsDir  = "cards"
 )
 
-// CraftDir is exported for convenience, sourced from workflow package.
-var CraftDir = workflow.CraftDir
-
 // PitchPath returns the path to the pitch file.
 func PitchPath() string {
-	return filepath.Join(CraftDir, PitchFile)
+	return filepath.Join(workflow.Dir(), PitchFile)
 }
 
 // CardsDirPath returns the path to the cards directory.
 func CardsDirPath() string {
-	return filepath.Join(CraftDir, CardsDir)
+	return filepath.Join(workflow.Dir(), CardsDir)
 }
 
 // EnsureStructureDir creates .craft/cards/ if it doesn't exist.
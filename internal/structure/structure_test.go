@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"craft/internal/workflow"
 )
 
 func setupTest(t *testing.T) func() {
@@ -11,12 +13,19 @@ func setupTest(t *testing.T) func() {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
 	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
 	return func() {
 		os.Chdir(origDir)
 	}
 }
 
 func TestPitchPath(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
 	want := filepath.Join(".craft", "pitch.md")
 	if got := PitchPath(); got != want {
 		t.Errorf("PitchPath() = %q, want %q", got, want)
@@ -24,12 +33,29 @@ func TestPitchPath(t *testing.T) {
 }
 
 func TestCardsDirPath(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
 	want := filepath.Join(".craft", "cards")
 	if got := CardsDirPath(); got != want {
 		t.Errorf("CardsDirPath() = %q, want %q", got, want)
 	}
 }
 
+func TestPathsFromSubdirectory(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	os.MkdirAll(workflow.DirName, 0755)
+	os.MkdirAll(filepath.Join("internal", "foo"), 0755)
+	os.Chdir(filepath.Join("internal", "foo"))
+
+	want := filepath.Join("..", "..", ".craft", "pitch.md")
+	if got := PitchPath(); got != want {
+		t.Errorf("PitchPath() = %q, want %q", got, want)
+	}
+}
+
 func TestEnsureStructureDir(t *testing.T) {
 	cleanup := setupTest(t)
 	defer cleanup()
@@ -57,7 +83,7 @@ func TestHasPitch(t *testing.T) {
 	}
 
 	// Create pitch
-	os.MkdirAll(CraftDir, 0755)
+	os.MkdirAll(workflow.DirName, 0755)
 	os.WriteFile(PitchPath(), []byte("# Pitch"), 0644)
 
 	if !HasPitch() {
@@ -139,7 +165,7 @@ func TestListStructure(t *testing.T) {
 	}
 
 	// Create pitch and cards
-	os.MkdirAll(CraftDir, 0755)
+	os.MkdirAll(workflow.DirName, 0755)
 	os.WriteFile(PitchPath(), []byte("# Pitch"), 0644)
 	EnsureStructureDir()
 	os.WriteFile(filepath.Join(CardsDirPath(), "01-first.md"), []byte("# Card"), 0644)
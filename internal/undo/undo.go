@@ -0,0 +1,252 @@
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"craft/internal/config"
+	"craft/internal/structure"
+	"craft/internal/workflow"
+)
+
+const (
+	UndoDir = "undo"
+
+	// ConfigGrace is how long after a change it can still be undone.
+	ConfigGrace  = "undo.grace"
+	DefaultGrace = 15 * time.Minute
+
+	// maxSnapshots bounds how many changes can be undone in a row.
+	maxSnapshots = 20
+
+	metaFile = "change"
+)
+
+// ErrNothingToUndo is returned when no snapshot exists.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Snapshot is the workflow, pitch and cards as they were before a change.
+type Snapshot struct {
+	Seq    int
+	Action string // Command that made the change, e.g. "accept"
+	At     time.Time
+	Dir    string
+}
+
+// Dir returns the directory holding snapshots.
+func Dir() string {
+	return filepath.Join(workflow.Dir(), UndoDir)
+}
+
+// Grace returns the undo window from the project config.
+func Grace() (time.Duration, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return 0, err
+	}
+	v, ok := cfg.Get(ConfigGrace)
+	if !ok {
+		return DefaultGrace, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", ConfigGrace, v)
+	}
+	return d, nil
+}
+
+// Save snapshots the current workflow, pitch and cards before action
+// changes them. Only the most recent snapshots are kept.
+func Save(action string) error {
+	snapshots, err := List()
+	if err != nil {
+		return err
+	}
+
+	seq := 1
+	if len(snapshots) > 0 {
+		seq = snapshots[len(snapshots)-1].Seq + 1
+	}
+	dir := filepath.Join(Dir(), strconv.Itoa(seq))
+	if err := os.MkdirAll(filepath.Join(dir, structure.CardsDir), 0755); err != nil {
+		return fmt.Errorf("failed to create undo snapshot: %w", err)
+	}
+
+	if err := copyFile(workflow.Path(), filepath.Join(dir, workflow.WorkflowFile)); err != nil {
+		return err
+	}
+	if structure.HasPitch() {
+		if err := copyFile(structure.PitchPath(), filepath.Join(dir, structure.PitchFile)); err != nil {
+			return err
+		}
+	}
+	cards, err := structure.ListCards()
+	if err != nil {
+		return fmt.Errorf("failed to read cards: %w", err)
+	}
+	for _, card := range cards {
+		if err := copyFile(card, filepath.Join(dir, structure.CardsDir, filepath.Base(card))); err != nil {
+			return err
+		}
+	}
+
+	meta := action + "\n" + time.Now().UTC().Format(time.RFC3339) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, metaFile), []byte(meta), 0644); err != nil {
+		return fmt.Errorf("failed to write undo snapshot: %w", err)
+	}
+
+	// Drop the oldest snapshots
+	for len(snapshots) >= maxSnapshots {
+		os.RemoveAll(snapshots[0].Dir)
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// List returns complete snapshots, oldest first.
+func List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read undo snapshots: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, e := range entries {
+		seq, err := strconv.Atoi(e.Name())
+		if !e.IsDir() || err != nil {
+			continue
+		}
+		dir := filepath.Join(Dir(), e.Name())
+		data, err := os.ReadFile(filepath.Join(dir, metaFile))
+		if err != nil {
+			// Interrupted while saving
+			continue
+		}
+		action, at, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+		t, _ := time.Parse(time.RFC3339, at)
+		snapshots = append(snapshots, Snapshot{Seq: seq, Action: action, At: t, Dir: dir})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Seq < snapshots[j].Seq })
+	return snapshots, nil
+}
+
+// Latest returns the most recent snapshot, or ErrNothingToUndo.
+func Latest() (Snapshot, error) {
+	snapshots, err := List()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(snapshots) == 0 {
+		return Snapshot{}, ErrNothingToUndo
+	}
+	return snapshots[len(snapshots)-1], nil
+}
+
+// Restore reverts the workflow, pitch and cards to s and removes it. History
+// and AI usage are not rolled back: the undo is appended to history so the
+// audit trail shows both the change and its reversal.
+func Restore(s Snapshot) (*workflow.Workflow, error) {
+	current, err := workflow.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.Dir, workflow.WorkflowFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read undo snapshot: %w", err)
+	}
+	w, err := workflow.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid undo snapshot: %w", err)
+	}
+
+	w.History = current.History
+	w.Usage = current.Usage
+	w.RecordTransition("Undid " + s.Action)
+
+	if err := restoreStructure(s.Dir); err != nil {
+		return nil, err
+	}
+	if err := w.Save(); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(s.Dir); err != nil {
+		return nil, fmt.Errorf("failed to remove undo snapshot: %w", err)
+	}
+	return w, nil
+}
+
+// Discard removes the most recent snapshot, for a change that failed
+// after its snapshot was taken.
+func Discard() error {
+	s, err := Latest()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(s.Dir)
+}
+
+// Clear removes all snapshots, e.g. when a workflow ends.
+func Clear() error {
+	if err := os.RemoveAll(Dir()); err != nil {
+		return fmt.Errorf("failed to clear undo snapshots: %w", err)
+	}
+	return nil
+}
+
+// restoreStructure replaces the pitch and cards with the snapshot's.
+func restoreStructure(dir string) error {
+	os.Remove(structure.PitchPath())
+	snapshotPitch := filepath.Join(dir, structure.PitchFile)
+	if _, err := os.Stat(snapshotPitch); err == nil {
+		if err := copyFile(snapshotPitch, structure.PitchPath()); err != nil {
+			return err
+		}
+	}
+
+	cards, err := structure.ListCards()
+	if err != nil {
+		return fmt.Errorf("failed to read cards: %w", err)
+	}
+	for _, card := range cards {
+		os.Remove(card)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, structure.CardsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read undo snapshot: %w", err)
+	}
+	if len(entries) > 0 {
+		if err := structure.EnsureStructureDir(); err != nil {
+			return err
+		}
+	}
+	for _, e := range entries {
+		src := filepath.Join(dir, structure.CardsDir, e.Name())
+		if err := copyFile(src, filepath.Join(structure.CardsDirPath(), e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
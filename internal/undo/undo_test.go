@@ -0,0 +1,133 @@
+package undo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"craft/internal/state"
+	"craft/internal/structure"
+	"craft/internal/workflow"
+)
+
+func setupTest(t *testing.T) func() {
+	t.Helper()
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return func() {
+		os.Chdir(origDir)
+	}
+}
+
+func TestSaveAndRestore(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	w := workflow.New("Undo test")
+	w.Save()
+	structure.EnsureStructureDir()
+	os.WriteFile(structure.PitchPath(), []byte("old pitch"), 0644)
+	os.WriteFile(filepath.Join(structure.CardsDirPath(), "01-old.md"), []byte("old card"), 0644)
+
+	if err := Save("accept"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// The change being undone
+	w.AddNote("accepted")
+	w.Transition(state.Shaping)
+	w.Save()
+	os.WriteFile(structure.PitchPath(), []byte("new pitch"), 0644)
+	os.Remove(filepath.Join(structure.CardsDirPath(), "01-old.md"))
+	os.WriteFile(filepath.Join(structure.CardsDirPath(), "01-new.md"), []byte("new card"), 0644)
+
+	s, err := Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if s.Action != "accept" || time.Since(s.At) > time.Minute {
+		t.Errorf("Latest() = %+v", s)
+	}
+
+	restored, err := Restore(s)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restored.State != state.Thinking || len(restored.Notes) != 0 {
+		t.Errorf("restored state = %s, notes = %v", restored.State, restored.Notes)
+	}
+
+	// History keeps the transition and records the undo
+	if len(restored.History) != 3 {
+		t.Fatalf("History = %+v, want 3 entries", restored.History)
+	}
+	if last := restored.History[2]; last.State != "thinking" || last.Note != "Undid accept" {
+		t.Errorf("last history entry = %+v", last)
+	}
+
+	if data, _ := os.ReadFile(structure.PitchPath()); string(data) != "old pitch" {
+		t.Errorf("pitch = %q, want old pitch", data)
+	}
+	cards, _ := structure.ListCards()
+	if len(cards) != 1 || filepath.Base(cards[0]) != "01-old.md" {
+		t.Errorf("cards = %v, want [01-old.md]", cards)
+	}
+
+	if _, err := Latest(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Latest() after restore error = %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestSaveKeepsRecentSnapshots(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	workflow.New("Many changes").Save()
+	for i := 0; i < maxSnapshots+5; i++ {
+		if err := Save("note"); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	snapshots, _ := List()
+	if len(snapshots) != maxSnapshots {
+		t.Errorf("kept %d snapshots, want %d", len(snapshots), maxSnapshots)
+	}
+	if snapshots[len(snapshots)-1].Seq != maxSnapshots+5 {
+		t.Errorf("latest seq = %d, want %d", snapshots[len(snapshots)-1].Seq, maxSnapshots+5)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if snapshots, _ := List(); len(snapshots) != 0 {
+		t.Errorf("List() after Clear() = %v", snapshots)
+	}
+}
+
+func TestGrace(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+
+	if g, err := Grace(); err != nil || g != DefaultGrace {
+		t.Errorf("Grace() = %s, %v, want default", g, err)
+	}
+
+	os.MkdirAll(".craft", 0755)
+	os.WriteFile(".craft/config.toml", []byte("[undo]\ngrace = \"1h\"\n"), 0644)
+	if g, _ := Grace(); g != time.Hour {
+		t.Errorf("Grace() = %s, want 1h", g)
+	}
+
+	os.WriteFile(".craft/config.toml", []byte("[undo]\ngrace = \"forever\"\n"), 0644)
+	if _, err := Grace(); err == nil {
+		t.Error("Grace() should reject an invalid duration")
+	}
+}
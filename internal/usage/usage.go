@@ -0,0 +1,153 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const envPrices = "CRAFT_AI_PRICES"
+
+// Call purposes.
+const (
+	PurposeReview = "review"
+	PurposePitch  = "pitch"
+	PurposeCards  = "cards"
+)
+
+// Call records the tokens consumed by a single AI request.
+type Call struct {
+	Model            string
+	Purpose          string // e.g., "review", "pitch", "cards"
+	PromptTokens     int
+	CompletionTokens int
+	At               time.Time
+}
+
+// Tokens returns the total tokens used by the call.
+func (c Call) Tokens() int {
+	return c.PromptTokens + c.CompletionTokens
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// PriceTable maps model names to prices.
+type PriceTable map[string]Price
+
+// defaultPrices covers common OpenAI-compatible models. Override or extend
+// with CRAFT_AI_PRICES="model=prompt/completion,...".
+var defaultPrices = PriceTable{
+	"gpt-4o-mini":  {Prompt: 0.15, Completion: 0.60},
+	"gpt-4o":       {Prompt: 2.50, Completion: 10.00},
+	"gpt-4.1":      {Prompt: 2.00, Completion: 8.00},
+	"gpt-4.1-mini": {Prompt: 0.40, Completion: 1.60},
+	"gpt-4.1-nano": {Prompt: 0.10, Completion: 0.40},
+}
+
+// Prices returns the default price table overlaid with CRAFT_AI_PRICES.
+func Prices() (PriceTable, error) {
+	table := make(PriceTable, len(defaultPrices))
+	for m, p := range defaultPrices {
+		table[m] = p
+	}
+
+	overrides, err := ParsePrices(os.Getenv(envPrices))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", envPrices, err)
+	}
+	for m, p := range overrides {
+		table[m] = p
+	}
+	return table, nil
+}
+
+// ParsePrices parses "model=prompt/completion" entries separated by commas.
+func ParsePrices(s string) (PriceTable, error) {
+	table := PriceTable{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, rates, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected model=prompt/completion, got %q", entry)
+		}
+		in, out, ok := strings.Cut(rates, "/")
+		if !ok {
+			return nil, fmt.Errorf("expected prompt/completion prices, got %q", rates)
+		}
+		prompt, err := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt price %q", in)
+		}
+		completion, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid completion price %q", out)
+		}
+		table[strings.TrimSpace(model)] = Price{Prompt: prompt, Completion: completion}
+	}
+	return table, nil
+}
+
+// Cost returns the estimated cost of a call in USD. The second result is
+// false if the model has no known price.
+func (t PriceTable) Cost(c Call) (float64, bool) {
+	p, ok := t.Lookup(c.Model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(c.PromptTokens)*p.Prompt + float64(c.CompletionTokens)*p.Completion) / 1e6, true
+}
+
+// Lookup returns the price of model. APIs answer with dated snapshots such
+// as gpt-4o-mini-2024-07-18, so without an exact match the longest name
+// that model extends with "-" is used.
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	if p, ok := t[model]; ok {
+		return p, true
+	}
+	best, found := "", false
+	for name := range t {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best, found = name, true
+		}
+	}
+	return t[best], found
+}
+
+// Summary aggregates a set of calls.
+type Summary struct {
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64 // USD, priced calls only
+	Unpriced         int     // Calls whose model has no known price
+}
+
+// Tokens returns the total tokens across all calls.
+func (s Summary) Tokens() int {
+	return s.PromptTokens + s.CompletionTokens
+}
+
+// Summarize totals calls using the given price table.
+func Summarize(calls []Call, prices PriceTable) Summary {
+	var s Summary
+	for _, c := range calls {
+		s.Calls++
+		s.PromptTokens += c.PromptTokens
+		s.CompletionTokens += c.CompletionTokens
+		if cost, ok := prices.Cost(c); ok {
+			s.Cost += cost
+		} else {
+			s.Unpriced++
+		}
+	}
+	return s
+}
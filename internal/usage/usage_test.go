@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestParsePrices(t *testing.T) {
+	table, err := ParsePrices("llama3=0/0, claude-x = 3/15")
+	if err != nil {
+		t.Fatalf("ParsePrices() error = %v", err)
+	}
+	if got := table["claude-x"]; got.Prompt != 3 || got.Completion != 15 {
+		t.Errorf("claude-x price = %+v, want 3/15", got)
+	}
+	if _, ok := table["llama3"]; !ok {
+		t.Error("llama3 should be priced")
+	}
+
+	invalid := []string{"gpt", "gpt=1", "gpt=a/1", "gpt=1/b"}
+	for _, s := range invalid {
+		if _, err := ParsePrices(s); err == nil {
+			t.Errorf("ParsePrices(%q) should return error", s)
+		}
+	}
+}
+
+func TestPricesOverride(t *testing.T) {
+	os.Setenv(envPrices, "gpt-4o-mini=1/2")
+	defer os.Unsetenv(envPrices)
+
+	table, err := Prices()
+	if err != nil {
+		t.Fatalf("Prices() error = %v", err)
+	}
+	if got := table["gpt-4o-mini"]; got.Prompt != 1 || got.Completion != 2 {
+		t.Errorf("override price = %+v, want 1/2", got)
+	}
+	if _, ok := table["gpt-4o"]; !ok {
+		t.Error("defaults should remain after override")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	prices := PriceTable{"m": {Prompt: 1, Completion: 2}}
+	calls := []Call{
+		{Model: "m", PromptTokens: 1000000, CompletionTokens: 500000},
+		{Model: "unknown", PromptTokens: 10, CompletionTokens: 5},
+	}
+
+	s := Summarize(calls, prices)
+	if s.Calls != 2 {
+		t.Errorf("Calls = %d, want 2", s.Calls)
+	}
+	if s.Tokens() != 1500015 {
+		t.Errorf("Tokens() = %d, want 1500015", s.Tokens())
+	}
+	if math.Abs(s.Cost-2.0) > 1e-9 {
+		t.Errorf("Cost = %v, want 2.0", s.Cost)
+	}
+	if s.Unpriced != 1 {
+		t.Errorf("Unpriced = %d, want 1", s.Unpriced)
+	}
+}
+
+func TestCostDatedModel(t *testing.T) {
+	prices := PriceTable{
+		"gpt-4o":      {Prompt: 2.50, Completion: 10.00},
+		"gpt-4o-mini": {Prompt: 0.15, Completion: 0.60},
+	}
+
+	tests := []struct {
+		model  string
+		want   float64
+		priced bool
+	}{
+		{"gpt-4o-mini", 0.15, true},
+		{"gpt-4o-mini-2024-07-18", 0.15, true}, // Longest prefix, not gpt-4o
+		{"gpt-4o-2024-08-06", 2.50, true},
+		{"gpt-4omni", 0, false}, // Prefixes must end at a "-"
+		{"llama3", 0, false},
+	}
+	for _, tt := range tests {
+		cost, ok := prices.Cost(Call{Model: tt.model, PromptTokens: 1000000})
+		if ok != tt.priced || math.Abs(cost-tt.want) > 1e-9 {
+			t.Errorf("Cost(%s) = %v, %v, want %v, %v", tt.model, cost, ok, tt.want, tt.priced)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"craft/internal/state"
+)
+
+// Duration units accepted in appetites, largest first.
+var durationUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+}
+
+// ParseAppetite parses a duration such as "2w", "3d", "1w2d" or "6h".
+func ParseAppetite(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("appetite required, e.g. 2w or 3d")
+	}
+
+	var total time.Duration
+	rest := s
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, fmt.Errorf("invalid appetite %q (use w, d, h or m, e.g. 2w)", s)
+		}
+		n, _ := strconv.Atoi(rest[:i])
+
+		var size time.Duration
+		for _, u := range durationUnits {
+			if rest[i:i+1] == u.suffix {
+				size = u.size
+				break
+			}
+		}
+		if size == 0 {
+			return 0, fmt.Errorf("invalid appetite %q (use w, d, h or m, e.g. 2w)", s)
+		}
+		total += time.Duration(n) * size
+		rest = rest[i+1:]
+	}
+
+	if total <= 0 {
+		return 0, fmt.Errorf("appetite must be positive")
+	}
+	return total, nil
+}
+
+// FormatDuration renders d in the units ParseAppetite accepts, e.g. "1w2d".
+// Anything under a minute is dropped.
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "0m"
+	}
+	var sb strings.Builder
+	for _, u := range durationUnits {
+		if n := d / u.size; n > 0 {
+			fmt.Fprintf(&sb, "%d%s", n, u.suffix)
+			d -= n * u.size
+		}
+	}
+	return sb.String()
+}
+
+// Phase is the total time a workflow spent in one state.
+type Phase struct {
+	State    state.State
+	Duration time.Duration
+}
+
+// Phases returns time spent in each state, in the order states were first
+// entered, computed from history. The current state runs until now; time
+// after shipping is not counted.
+func (w *Workflow) Phases(now time.Time) []Phase {
+	var phases []Phase
+	index := make(map[state.State]int)
+
+	for i, h := range w.History {
+		s := state.State(h.State)
+		if s == state.Shipped {
+			continue
+		}
+		end := now
+		if i+1 < len(w.History) {
+			end = w.History[i+1].At
+		}
+		d := end.Sub(h.At)
+		if d < 0 {
+			d = 0
+		}
+
+		if j, ok := index[s]; ok {
+			phases[j].Duration += d
+		} else {
+			index[s] = len(phases)
+			phases = append(phases, Phase{State: s, Duration: d})
+		}
+	}
+	return phases
+}
+
+// PhaseDuration returns the time spent in s.
+func (w *Workflow) PhaseDuration(s state.State, now time.Time) time.Duration {
+	for _, p := range w.Phases(now) {
+		if p.State == s {
+			return p.Duration
+		}
+	}
+	return 0
+}
+
+// AppetiteExceeded returns true if an appetite is set and building has run past it.
+func (w *Workflow) AppetiteExceeded(now time.Time) bool {
+	return w.Appetite > 0 && w.PhaseDuration(state.Building, now) > w.Appetite
+}
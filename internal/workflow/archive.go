@@ -0,0 +1,103 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"craft/internal/state"
+)
+
+const (
+	ArchiveDir = "archive"
+
+	// NoteAbandoned marks the history entry added when an unshipped
+	// workflow is archived.
+	NoteAbandoned = "Abandoned"
+
+	archiveTimeFormat = "20060102T150405Z"
+)
+
+// ArchivePath returns the directory holding finished workflows.
+func ArchivePath() string {
+	return filepath.Join(Dir(), ArchiveDir)
+}
+
+// Archive copies the workflow file into the archive and returns the new
+// path. Unshipped workflows get a final "Abandoned" history entry so the
+// time spent in their last phase is known. A file that cannot be parsed is
+// archived as is.
+func Archive() (string, error) {
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		return "", fmt.Errorf("failed to read workflow: %w", err)
+	}
+
+	startedAt := time.Now().UTC()
+	if w, err := Parse(data); err == nil {
+		if w.State != state.Shipped {
+			w.migrateSchema()
+			w.RecordTransition(NoteAbandoned)
+			data = []byte(w.Format())
+		}
+		if !w.StartedAt.IsZero() {
+			startedAt = w.StartedAt.UTC()
+		}
+	}
+
+	if err := os.MkdirAll(ArchivePath(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Two workflows started in the same second get a numeric suffix
+	base := startedAt.Format(archiveTimeFormat)
+	path := filepath.Join(ArchivePath(), base+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(ArchivePath(), fmt.Sprintf("%s-%d.md", base, i))
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to archive workflow: %w", err)
+	}
+	return path, nil
+}
+
+// LoadArchive parses every archived workflow, oldest first. Files that
+// cannot be parsed are skipped.
+func LoadArchive() ([]*Workflow, error) {
+	entries, err := os.ReadDir(ArchivePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var workflows []*Workflow
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(ArchivePath(), name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archived workflow: %w", err)
+		}
+		w, err := Parse(data)
+		if err != nil {
+			continue
+		}
+		workflows = append(workflows, w)
+	}
+	return workflows, nil
+}
@@ -0,0 +1,141 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Concern kinds, named after the command that raises them.
+const (
+	ConcernReject = "reject"
+	ConcernRevise = "revise"
+)
+
+// Concern statuses.
+const (
+	ConcernOpen     = "open"
+	ConcernResolved = "resolved"
+	ConcernWaived   = "waived"
+)
+
+// Concern is a tracked objection that must be answered or waived before
+// the workflow advances past the phase it was raised in.
+type Concern struct {
+	ID         string // C1, C2, ...
+	Kind       string // ConcernReject or ConcernRevise
+	Text       string
+	RaisedAt   time.Time
+	Status     string
+	Answer     string // Resolution, or reason for waiving
+	ResolvedAt time.Time
+}
+
+// Open returns true if the concern has not been resolved or waived.
+func (c Concern) Open() bool {
+	return c.Status == ConcernOpen
+}
+
+// RaiseConcern records a new open concern and returns it.
+func (w *Workflow) RaiseConcern(kind, text string) Concern {
+	c := Concern{
+		ID:       fmt.Sprintf("C%d", len(w.Concerns)+1),
+		Kind:     kind,
+		Text:     text,
+		RaisedAt: time.Now().UTC(),
+		Status:   ConcernOpen,
+	}
+	w.Concerns = append(w.Concerns, c)
+	return c
+}
+
+// ResolveConcern closes an open concern with an answer, or waives it with
+// a reason. The outcome is recorded in history.
+func (w *Workflow) ResolveConcern(id, answer string, waive bool) error {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		if waive {
+			return fmt.Errorf("a reason is required to waive a concern")
+		}
+		return fmt.Errorf("an answer is required to resolve a concern")
+	}
+
+	for i := range w.Concerns {
+		c := &w.Concerns[i]
+		if !strings.EqualFold(c.ID, id) {
+			continue
+		}
+		if !c.Open() {
+			return fmt.Errorf("concern %s is already %s", c.ID, c.Status)
+		}
+
+		c.Status = ConcernResolved
+		verb := "Resolved"
+		if waive {
+			c.Status = ConcernWaived
+			verb = "Waived"
+		}
+		c.Answer = answer
+		c.ResolvedAt = time.Now().UTC()
+		w.RecordTransition(fmt.Sprintf("%s %s: %s", verb, c.ID, answer))
+		return nil
+	}
+	return fmt.Errorf("no concern %s", id)
+}
+
+// OpenConcerns returns unresolved concerns of the given kind, or of any
+// kind if kind is empty.
+func (w *Workflow) OpenConcerns(kind string) []Concern {
+	var open []Concern
+	for _, c := range w.Concerns {
+		if c.Open() && (kind == "" || c.Kind == kind) {
+			open = append(open, c)
+		}
+	}
+	return open
+}
+
+// formatConcerns returns the concerns ledger formatted for the workflow file.
+func (w *Workflow) formatConcerns() string {
+	if len(w.Concerns) == 0 {
+		return ""
+	}
+	var lines []string
+	for _, c := range w.Concerns {
+		entry := fmt.Sprintf("  - %s: %s\n    %s: %s\n    %s: %s\n    %s: %s\n    %s: %s",
+			keyID, c.ID,
+			keyKind, c.Kind,
+			keyText, quote(c.Text),
+			keyAt, c.RaisedAt.Format(time.RFC3339),
+			keyStatus, c.Status)
+		if c.Answer != "" {
+			entry += fmt.Sprintf("\n    %s: %s", keyAnswer, quote(c.Answer))
+		}
+		if !c.ResolvedAt.IsZero() {
+			entry += fmt.Sprintf("\n    %s: %s", keyResolvedAt, c.ResolvedAt.Format(time.RFC3339))
+		}
+		lines = append(lines, entry)
+	}
+	return keyConcerns + ":\n" + strings.Join(lines, "\n")
+}
+
+// parseConcerns reads the concerns ledger from front matter list items.
+func parseConcerns(items []map[string]string) []Concern {
+	var concerns []Concern
+	for _, item := range items {
+		c := Concern{
+			ID:         item[keyID],
+			Kind:       item[keyKind],
+			Text:       item[keyText],
+			RaisedAt:   parseTime(item[keyAt]),
+			Status:     item[keyStatus],
+			Answer:     item[keyAnswer],
+			ResolvedAt: parseTime(item[keyResolvedAt]),
+		}
+		if c.Status == "" {
+			c.Status = ConcernOpen
+		}
+		concerns = append(concerns, c)
+	}
+	return concerns
+}
@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"craft/internal/identity"
+	"craft/internal/state"
+)
+
+// Headings that delimit the intent in the workflow file.
+const (
+	intentHeading = "# Intent"
+	notesHeading  = "## Notes"
+)
+
+// CleanIntent normalizes a multi-line intent for the workflow file:
+// line endings become \n, trailing spaces and surrounding blank lines are
+// dropped and runs of blank lines become one, so paragraphs and lists
+// survive a load and save unchanged.
+func CleanIntent(text string) (string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if trimmed := strings.TrimSpace(line); trimmed == intentHeading || trimmed == notesHeading {
+			return "", fmt.Errorf("the intent can't contain a %q line; it delimits the intent in the workflow file", trimmed)
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return "", errors.New("intent cannot be empty")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Title returns the first line of the intent, without markdown heading
+// or list markers, for one-line displays.
+func (w *Workflow) Title() string {
+	line, _, _ := strings.Cut(w.Intent, "\n")
+	line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+	for _, marker := range []string{"- ", "* "} {
+		line = strings.TrimPrefix(line, marker)
+	}
+	return line
+}
+
+// IntentRevision is a version of the intent that was replaced while
+// thinking.
+type IntentRevision struct {
+	Intent string    // The intent before the revision
+	At     time.Time // When it was replaced
+	Actor  string
+	Reason string
+}
+
+// ReviseIntent replaces the intent, keeping the previous version with the
+// reason for the change. The intent can only change while thinking.
+func (w *Workflow) ReviseIntent(text, reason string) error {
+	if w.State != state.Thinking {
+		return fmt.Errorf("the intent is frozen once accepted (current state: %s)", w.State)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("a reason is required to revise the intent")
+	}
+	intent, err := CleanIntent(text)
+	if err != nil {
+		return err
+	}
+	if intent == w.Intent {
+		return errors.New("the intent is unchanged")
+	}
+
+	w.Revisions = append(w.Revisions, IntentRevision{
+		Intent: w.Intent,
+		At:     time.Now().UTC(),
+		Actor:  identity.Current(),
+		Reason: reason,
+	})
+	w.Intent = intent
+	w.RecordTransition(fmt.Sprintf("Revised intent (r%d): %s", len(w.Revisions), reason))
+	return nil
+}
+
+// HashIntent returns the checksum of the current intent, recorded as
+// FrozenIntent when it is accepted.
+func (w *Workflow) HashIntent() string {
+	return ComputeChecksum([]byte(w.Intent))
+}
+
+// IntentChangedSinceAccept reports whether the intent no longer matches
+// the one frozen at accept, e.g. after a hand edit of the workflow file.
+func (w *Workflow) IntentChangedSinceAccept() bool {
+	return w.FrozenIntent != "" && w.FrozenIntent != w.HashIntent()
+}
+
+// formatRevisions returns prior intents formatted for the workflow file.
+func (w *Workflow) formatRevisions() string {
+	if len(w.Revisions) == 0 {
+		return ""
+	}
+	var lines []string
+	for _, r := range w.Revisions {
+		entry := fmt.Sprintf("  - %s: %s\n    %s: %s", keyIntent, quote(r.Intent), keyAt, r.At.Format(time.RFC3339))
+		if r.Actor != "" {
+			entry += fmt.Sprintf("\n    %s: %s", keyActor, quote(r.Actor))
+		}
+		entry += fmt.Sprintf("\n    %s: %s", keyReason, quote(r.Reason))
+		lines = append(lines, entry)
+	}
+	return keyRevisions + ":\n" + strings.Join(lines, "\n")
+}
+
+// parseRevisions reads prior intents from front matter list items.
+func parseRevisions(items []map[string]string) []IntentRevision {
+	var revisions []IntentRevision
+	for _, item := range items {
+		revisions = append(revisions, IntentRevision{
+			Intent: item[keyIntent],
+			At:     parseTime(item[keyAt]),
+			Actor:  item[keyActor],
+			Reason: item[keyReason],
+		})
+	}
+	return revisions
+}
@@ -0,0 +1,196 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"craft/internal/identity"
+	"craft/internal/state"
+)
+
+// Note types. NoteGeneral is used when no type is given.
+const (
+	NoteGeneral    = "note"
+	NoteDecision   = "decision"
+	NoteRisk       = "risk"
+	NoteAssumption = "assumption"
+	NoteQuestion   = "question"
+)
+
+// NoteTypes lists note types in display order.
+var NoteTypes = []string{NoteDecision, NoteRisk, NoteAssumption, NoteQuestion, NoteGeneral}
+
+// Note metadata keys, written in a trailing HTML comment on the note line.
+const (
+	noteKeyType   = "type"
+	noteKeyAuthor = "author"
+	noteKeyAt     = "at"
+	noteKeyPhase  = "phase"
+
+	noteMetaOpen  = "<!-- "
+	noteMetaClose = " -->"
+
+	// Comments in a note's text are written escaped, as Markdown shows
+	// them, so they can't be read back as its metadata.
+	noteComment        = "<!--"
+	noteCommentEscaped = `\<!--`
+)
+
+// Note is a piece of deliberation recorded against the workflow.
+type Note struct {
+	Text   string
+	Type   string
+	Author string
+	At     time.Time
+	Phase  state.State
+}
+
+// ValidNoteType returns true if t is a known note type.
+func ValidNoteType(t string) bool {
+	for _, nt := range NoteTypes {
+		if t == nt {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNote appends a general note to the workflow.
+func (w *Workflow) AddNote(note string) {
+	w.AddTypedNote(NoteGeneral, note)
+}
+
+// AddTypedNote appends a note of the given type, attributed to the current
+// user and stamped with the time and current phase.
+func (w *Workflow) AddTypedNote(noteType, text string) {
+	// Notes are single markdown list items
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\n", " ")
+	text = strings.Trim(text, "\"'")
+	if text == "" {
+		return
+	}
+	w.Notes = append(w.Notes, Note{
+		Text:   text,
+		Type:   noteType,
+		Author: identity.Current(),
+		At:     time.Now().UTC(),
+		Phase:  w.State,
+	})
+}
+
+// NotesByType returns notes of type t, in the order they were added.
+func (w *Workflow) NotesByType(t string) []Note {
+	var notes []Note
+	for _, n := range w.Notes {
+		if n.Type == t {
+			notes = append(notes, n)
+		}
+	}
+	return notes
+}
+
+// NoteTexts returns the notes as plain lines for reviewers and shapers.
+// Typed notes are prefixed with their type, e.g. "[risk] ...".
+func (w *Workflow) NoteTexts() []string {
+	texts := make([]string, len(w.Notes))
+	for i, n := range w.Notes {
+		if n.Type == NoteGeneral {
+			texts[i] = n.Text
+		} else {
+			texts[i] = "[" + n.Type + "] " + n.Text
+		}
+	}
+	return texts
+}
+
+// formatNote renders a note as a markdown list item. Metadata goes in a
+// trailing comment so the body stays readable; notes without metadata
+// (written before notes had any) are rendered as plain text.
+func formatNote(n Note) string {
+	var meta []string
+	if n.Type != NoteGeneral {
+		meta = append(meta, noteKeyType+"="+n.Type)
+	}
+	if n.Author != "" {
+		meta = append(meta, noteKeyAuthor+"="+quote(n.Author))
+	}
+	if !n.At.IsZero() {
+		meta = append(meta, noteKeyAt+"="+n.At.Format(time.RFC3339))
+	}
+	if n.Phase != "" {
+		meta = append(meta, noteKeyPhase+"="+string(n.Phase))
+	}
+
+	text := strings.ReplaceAll(n.Text, noteComment, noteCommentEscaped)
+	if len(meta) == 0 {
+		return "- " + text
+	}
+	return fmt.Sprintf("- %s %s%s%s", text, noteMetaOpen, strings.Join(meta, " "), noteMetaClose)
+}
+
+// parseNote reads a list item written by formatNote.
+func parseNote(item string) Note {
+	n := Note{Text: unescapeNoteText(item), Type: NoteGeneral}
+
+	if !strings.HasSuffix(item, noteMetaClose) {
+		return n
+	}
+	start := strings.LastIndex(item, noteMetaOpen)
+	if start < 0 || strings.HasSuffix(item[:start], `\`) {
+		return n
+	}
+	meta := item[start+len(noteMetaOpen) : len(item)-len(noteMetaClose)]
+	n.Text = unescapeNoteText(strings.TrimSpace(item[:start]))
+
+	for _, field := range splitNoteMeta(meta) {
+		key, value, _ := strings.Cut(field, "=")
+		value = unquote(value)
+		switch key {
+		case noteKeyType:
+			n.Type = value
+		case noteKeyAuthor:
+			n.Author = value
+		case noteKeyAt:
+			n.At = parseTime(value)
+		case noteKeyPhase:
+			n.Phase = state.State(value)
+		}
+	}
+	return n
+}
+
+// unescapeNoteText reverses the escaping of comments done by formatNote.
+func unescapeNoteText(s string) string {
+	return strings.ReplaceAll(s, noteCommentEscaped, noteComment)
+}
+
+// splitNoteMeta splits on spaces outside quoted values.
+func splitNoteMeta(s string) []string {
+	var fields []string
+	var sb strings.Builder
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && inQuote && i+1 < len(s):
+			sb.WriteByte(c)
+			i++
+			sb.WriteByte(s[i])
+		case c == '"':
+			inQuote = !inQuote
+			sb.WriteByte(c)
+		case c == ' ' && !inQuote:
+			if sb.Len() > 0 {
+				fields = append(fields, sb.String())
+				sb.Reset()
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if sb.Len() > 0 {
+		fields = append(fields, sb.String())
+	}
+	return fields
+}
@@ -0,0 +1,82 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"craft/internal/identity"
+	"craft/internal/state"
+)
+
+// Handoff makes to the owner of the workflow. The context, where things
+// stand and what comes next, is recorded in history with both names.
+func (w *Workflow) Handoff(to, context string) error {
+	to = strings.TrimSpace(to)
+	context = strings.TrimSpace(context)
+	if to == "" {
+		return errors.New("a new owner is required")
+	}
+	if context == "" {
+		return errors.New("context is required so the new owner knows where things stand")
+	}
+	if w.Owner != "" && identity.Same(to, w.Owner) {
+		return fmt.Errorf("%s already owns this workflow", to)
+	}
+
+	from := w.Owner
+	if from == "" {
+		from = "nobody"
+	}
+	w.Owner = to
+	w.RecordTransition(fmt.Sprintf("Handed off from %s to %s: %s", from, to, context))
+	return nil
+}
+
+// Contributors returns everyone other than the owner who recorded history
+// or notes, in the order they first appeared.
+func (w *Workflow) Contributors() []string {
+	seen := map[string]bool{w.Owner: true, "": true}
+	var people []string
+	add := func(person string) {
+		if !seen[person] {
+			seen[person] = true
+			people = append(people, person)
+		}
+	}
+	for _, h := range w.History {
+		add(h.Actor)
+	}
+	for _, n := range w.Notes {
+		add(n.Author)
+	}
+	return people
+}
+
+// AcceptedBy returns who made the most recent accept, the transition out
+// of thinking, or "" if it wasn't recorded.
+func (w *Workflow) AcceptedBy() string {
+	accepter := ""
+	for i := 1; i < len(w.History); i++ {
+		if w.History[i-1].State == string(state.Thinking) && w.History[i].State != string(state.Thinking) {
+			accepter = w.History[i].Actor
+		}
+	}
+	return accepter
+}
+
+// CheckTwoPerson enforces the two-person rule for actor: whoever accepted
+// the intent may not approve or ship it. It returns the accepter so the
+// separation can be recorded.
+func (w *Workflow) CheckTwoPerson(actor string) (string, error) {
+	accepter := w.AcceptedBy()
+	switch {
+	case actor == "":
+		return "", errors.New("the two-person rule needs to know who you are: set git user.name and user.email")
+	case accepter == "":
+		return "", errors.New("the two-person rule can't be checked: accept was not recorded with an identity")
+	case identity.Same(actor, accepter):
+		return "", fmt.Errorf("the two-person rule is on and %s accepted this workflow; someone else must approve and ship it", accepter)
+	}
+	return accepter, nil
+}
@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RegistryFile lists, in the repository root's .craft directory, the
+// package directories that hold their own workflow.
+const RegistryFile = "registry"
+
+// RepoRoot returns the git root above the project root, or the project
+// root itself outside a git repository.
+func RepoRoot() string {
+	root, err := filepath.Abs(Root())
+	if err != nil {
+		return Root()
+	}
+	for d := root; ; d = filepath.Dir(d) {
+		if exists(filepath.Join(d, ".git")) {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return root
+		}
+	}
+}
+
+// RegistryPath returns the path to the repository's registry.
+func RegistryPath() string {
+	return filepath.Join(RepoRoot(), DirName, RegistryFile)
+}
+
+// Register records the project directory in the repository's registry so
+// `craft status --all` finds it. The repository root needs no entry.
+func Register() error {
+	root, err := filepath.Abs(Root())
+	if err != nil {
+		return fmt.Errorf("failed to register workflow: %w", err)
+	}
+	rel, err := filepath.Rel(RepoRoot(), root)
+	if err != nil || rel == "." {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	dirs, err := Registered()
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		if d == rel {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(RegistryPath()), 0755); err != nil {
+		return fmt.Errorf("failed to register workflow: %w", err)
+	}
+	f, err := os.OpenFile(RegistryPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to register workflow: %w", err)
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, rel); err != nil {
+		return fmt.Errorf("failed to register workflow: %w", err)
+	}
+	return nil
+}
+
+// Registered returns the directories that may hold a workflow, relative to
+// the repository root with forward slashes: the root itself first, then
+// each registered package.
+func Registered() ([]string, error) {
+	dirs := []string{"."}
+	data, err := os.ReadFile(RegistryPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dirs, nil
+		}
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs, nil
+}
+
+// skipDirs are never searched for workflows: they hold dependencies or
+// build output, not packages.
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Workflows returns the directories holding a workflow, relative to the
+// repository root with forward slashes: the registered ones, then any
+// others found by walking the repository, sorted. The root comes first
+// whether or not it has a workflow.
+func Workflows() ([]string, error) {
+	dirs, err := Registered()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, d := range dirs {
+		seen[d] = true
+	}
+
+	repo := RepoRoot()
+	var found []string
+	err = filepath.WalkDir(repo, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories can't hold a workflow we could load
+			if d != nil && d.IsDir() && path != repo {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if skipDirs[d.Name()] {
+			return fs.SkipDir
+		}
+		if d.Name() != DirName {
+			return nil
+		}
+		if exists(filepath.Join(path, WorkflowFile)) {
+			rel, err := filepath.Rel(repo, filepath.Dir(path))
+			if err == nil && !seen[filepath.ToSlash(rel)] {
+				seen[filepath.ToSlash(rel)] = true
+				found = append(found, filepath.ToSlash(rel))
+			}
+		}
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for workflows: %w", err)
+	}
+	sort.Strings(found)
+	return append(dirs, found...), nil
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReviewsDir holds the output of `craft think --review`, one file per review.
+const ReviewsDir = "reviews"
+
+// ReviewsPath returns the directory holding saved reviews.
+func ReviewsPath() string {
+	return filepath.Join(Dir(), ReviewsDir)
+}
+
+// SaveReview writes a reviewer's feedback to the reviews directory and
+// returns the new path. Files are named after the time and reviewer so
+// they sort oldest first.
+func SaveReview(reviewer, content string) (string, error) {
+	if err := os.MkdirAll(ReviewsPath(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create reviews directory: %w", err)
+	}
+
+	now := time.Now().UTC()
+	base := now.Format(archiveTimeFormat) + "-" + strings.ToLower(reviewer)
+	path := filepath.Join(ReviewsPath(), base+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(ReviewsPath(), fmt.Sprintf("%s-%d.md", base, i))
+	}
+
+	data := fmt.Sprintf("---\nreviewer: %s\n%s: %s\n---\n\n%s\n",
+		reviewer, keyAt, now.Format(time.RFC3339), strings.TrimSpace(content))
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return "", fmt.Errorf("failed to save review: %w", err)
+	}
+	return path, nil
+}
+
+// ListReviews returns paths to saved reviews, oldest first.
+func ListReviews() ([]string, error) {
+	entries, err := os.ReadDir(ReviewsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read reviews: %w", err)
+	}
+
+	var reviews []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".md" {
+			reviews = append(reviews, filepath.Join(ReviewsPath(), e.Name()))
+		}
+	}
+	sort.Strings(reviews)
+	return reviews, nil
+}
+
+// ClearReviews removes the saved reviews, which belong to the workflow
+// being abandoned.
+func ClearReviews() error {
+	if err := os.RemoveAll(ReviewsPath()); err != nil {
+		return fmt.Errorf("failed to clear reviews: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DirName is the name of the directory holding the workflow and its files.
+	DirName = ".craft"
+
+	// EnvDir names the project directory to use instead of searching, e.g.
+	// one package of a monorepo. Set by the global --dir flag.
+	EnvDir = "CRAFT_DIR"
+)
+
+// Dir returns the .craft directory for the working directory. Like git
+// with .git, it is the nearest one found walking up from the working
+// directory, stopping at the git root or the filesystem root. If there is
+// none, it is where `craft start` creates one: the git root, or the
+// working directory outside a git repository.
+//
+// The path is relative to the working directory when possible, so it
+// stays ".craft" when run from the project root. CRAFT_DIR overrides the
+// search.
+func Dir() string {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return filepath.Join(dir, DirName)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return DirName
+	}
+	return relative(cwd, filepath.Join(findRoot(cwd), DirName))
+}
+
+// Root returns the project directory that contains Dir.
+func Root() string {
+	return filepath.Dir(Dir())
+}
+
+// findRoot returns the nearest directory from dir upwards that contains
+// .craft. Without one it returns the git root, or dir itself.
+func findRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if isDir(filepath.Join(d, DirName)) {
+			return d
+		}
+		// .git is a file in worktrees and submodules
+		if exists(filepath.Join(d, ".git")) {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// relative returns target relative to base, or target itself if that
+// is not possible.
+func relative(base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return target
+	}
+	return rel
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -7,14 +7,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"craft/internal/identity"
 	"craft/internal/state"
+	"craft/internal/usage"
 )
 
 const (
-	CraftDir      = ".craft"
 	WorkflowFile  = "workflow.md"
 	SchemaVersion = 3
 )
@@ -25,16 +27,40 @@ const (
 	keySchemaVersion = "schema_version"
 	keyChecksum      = "checksum"
 	keyStartedAt     = "started_at"
-	keyHistory       = "history:"
-	keyHistoryState  = "- state:"
-	keyHistoryAt     = "at:"
-	keyHistoryNote   = "note:"
+	keyOwner         = "owner"
+	keyTwoPerson     = "two_person"
+	keyFrozenIntent  = "intent_hash"
+	keyBudget        = "budget_usd"
+	keyAppetite      = "appetite"
+	keyHistory       = "history"
+	keyUsage         = "usage"
+	keyConcerns      = "concerns"
+	keyRevisions     = "revisions"
+
+	// List item fields
+	keyAt               = "at"
+	keyNote             = "note"
+	keyActor            = "actor"
+	keyModel            = "model"
+	keyPurpose          = "purpose"
+	keyPromptTokens     = "prompt_tokens"
+	keyCompletionTokens = "completion_tokens"
+	keyID               = "id"
+	keyKind             = "kind"
+	keyText             = "text"
+	keyStatus           = "status"
+	keyAnswer           = "answer"
+	keyResolvedAt       = "resolved_at"
+	keyIntent           = "intent"
+	keyReason           = "reason"
 )
 
-// HistoryEntry records a state transition with timestamp and optional note.
+// HistoryEntry records a state transition with timestamp, the person who
+// made it and an optional note.
 type HistoryEntry struct {
 	State string
 	At    time.Time
+	Actor string // Empty in history written before actors were tracked
 	Note  string
 }
 
@@ -44,14 +70,22 @@ type Workflow struct {
 	SchemaVersion int
 	Checksum      string
 	StartedAt     time.Time
+	Owner         string // Person responsible; see Handoff
+	TwoPerson     bool   // Approve and ship need someone other than the accepter
 	History       []HistoryEntry
+	Budget        float64       // USD; zero means no budget
+	Appetite      time.Duration // Time allowed for building; zero means none
+	Usage         []usage.Call  // AI calls made for this workflow
+	Concerns      []Concern     // Raised by reject and revise
 	Intent        string
-	Notes         []string
+	Revisions     []IntentRevision // Prior intents, oldest first
+	FrozenIntent  string           // Hash of the intent when accepted
+	Notes         []Note
 }
 
 // Path returns the full path to the workflow file.
 func Path() string {
-	return filepath.Join(CraftDir, WorkflowFile)
+	return filepath.Join(Dir(), WorkflowFile)
 }
 
 // Exists returns true if a workflow file exists.
@@ -62,12 +96,18 @@ func Exists() bool {
 
 // EnsureDir creates the .craft directory if it doesn't exist.
 func EnsureDir() error {
-	return os.MkdirAll(CraftDir, 0755)
+	return os.MkdirAll(Dir(), 0755)
 }
 
 // Load reads and parses the workflow file.
 func Load() (*Workflow, error) {
-	data, err := os.ReadFile(Path())
+	return LoadFile(Path())
+}
+
+// LoadFile reads and parses the workflow file at path, e.g. another
+// package's workflow.
+func LoadFile(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, errors.New("no workflow found")
@@ -82,7 +122,7 @@ func Load() (*Workflow, error) {
 
 	// Handle v1 migration in Load (not Parse) since it may need filesystem access
 	if w.SchemaVersion < 2 && len(w.History) == 0 {
-		w.synthesizeV1History(Path())
+		w.synthesizeV1History(path)
 	}
 
 	return w, nil
@@ -143,111 +183,171 @@ func extractFrontMatter(content string) (frontMatter, body string, err error) {
 	return parts[0], parts[1], nil
 }
 
-// parseFrontMatter parses YAML front matter into the workflow struct.
-func parseFrontMatter(frontMatter string, w *Workflow) {
-	lines := strings.Split(frontMatter, "\n")
-	inHistory := false
-	var currentEntry *HistoryEntry
+// frontMatter holds the raw scalar fields and list sections of a workflow header.
+type frontMatter struct {
+	fields map[string]string
+	lists  map[string][]map[string]string
+}
 
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+// parseFrontMatter parses YAML front matter into the workflow struct.
+func parseFrontMatter(text string, w *Workflow) {
+	fm := splitFrontMatter(text)
 
-		// Check for history array start
-		if trimmed == keyHistory {
-			inHistory = true
-			continue
+	for key, value := range fm.fields {
+		switch key {
+		case keyState:
+			w.State = state.State(value)
+		case keySchemaVersion:
+			fmt.Sscanf(value, "%d", &w.SchemaVersion)
+		case keyChecksum:
+			w.Checksum = value
+		case keyStartedAt:
+			w.StartedAt = parseTime(value)
+		case keyOwner:
+			w.Owner = value
+		case keyTwoPerson:
+			w.TwoPerson = value == "true"
+		case keyFrozenIntent:
+			w.FrozenIntent = value
+		case keyBudget:
+			w.Budget, _ = strconv.ParseFloat(value, 64)
+		case keyAppetite:
+			w.Appetite, _ = ParseAppetite(value)
 		}
+	}
 
-		// Handle history entries
-		if inHistory {
-			if trimmed == "" {
-				continue
-			}
+	for _, item := range fm.lists[keyHistory] {
+		w.History = append(w.History, HistoryEntry{
+			State: item[keyState],
+			At:    parseTime(item[keyAt]),
+			Actor: item[keyActor],
+			Note:  item[keyNote],
+		})
+	}
 
-			// New history entry starts with "- state:"
-			if strings.HasPrefix(trimmed, keyHistoryState) {
-				if currentEntry != nil {
-					w.History = append(w.History, *currentEntry)
-				}
-				currentEntry = &HistoryEntry{
-					State: strings.TrimSpace(strings.TrimPrefix(trimmed, keyHistoryState)),
-				}
-				continue
-			}
+	w.Concerns = parseConcerns(fm.lists[keyConcerns])
+	w.Revisions = parseRevisions(fm.lists[keyRevisions])
 
-			// If line doesn't start with whitespace, we're done with history
-			if !strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "\t") {
-				if currentEntry != nil {
-					w.History = append(w.History, *currentEntry)
-					currentEntry = nil
-				}
-				inHistory = false
-				// Fall through to process this line as a regular key
-			} else if currentEntry != nil {
-				parseHistoryEntryField(trimmed, currentEntry)
-				continue
-			}
+	for _, item := range fm.lists[keyUsage] {
+		c := usage.Call{
+			Model:   item[keyModel],
+			Purpose: item[keyPurpose],
+			At:      parseTime(item[keyAt]),
+		}
+		c.PromptTokens, _ = strconv.Atoi(item[keyPromptTokens])
+		c.CompletionTokens, _ = strconv.Atoi(item[keyCompletionTokens])
+		w.Usage = append(w.Usage, c)
+	}
+}
+
+// splitFrontMatter separates top-level "key: value" fields from list
+// sections ("key:" followed by indented "- field: value" items).
+func splitFrontMatter(text string) frontMatter {
+	fm := frontMatter{
+		fields: make(map[string]string),
+		lists:  make(map[string][]map[string]string),
+	}
+
+	var list string
+	var item map[string]string
+	flush := func() {
+		if item != nil {
+			fm.lists[list] = append(fm.lists[list], item)
+			item = nil
 		}
+	}
 
-		// Regular key-value parsing
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
 		if trimmed == "" {
 			continue
 		}
-		kv := strings.SplitN(trimmed, ":", 2)
-		if len(kv) != 2 {
+
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		if list != "" && (indented || strings.HasPrefix(trimmed, "- ")) {
+			// New list item starts with "- "
+			if strings.HasPrefix(trimmed, "- ") {
+				flush()
+				item = make(map[string]string)
+				trimmed = strings.TrimPrefix(trimmed, "- ")
+			}
+			if item != nil {
+				if key, value, ok := splitKeyValue(trimmed); ok {
+					item[key] = value
+				}
+			}
 			continue
 		}
-		key := strings.TrimSpace(kv[0])
-		value := strings.TrimSpace(kv[1])
 
-		switch key {
-		case keyState:
-			w.State = state.State(value)
-		case keySchemaVersion:
-			fmt.Sscanf(value, "%d", &w.SchemaVersion)
-		case keyChecksum:
-			w.Checksum = value
-		case keyStartedAt:
-			if t, err := time.Parse(time.RFC3339, value); err == nil {
-				w.StartedAt = t
-			}
+		// Unindented line ends any list section
+		flush()
+		list = ""
+
+		key, value, ok := splitKeyValue(trimmed)
+		if !ok {
+			continue
+		}
+		if value == "" {
+			list = key
+			continue
 		}
+		fm.fields[key] = value
 	}
+	flush()
 
-	// Don't forget last history entry
-	if currentEntry != nil {
-		w.History = append(w.History, *currentEntry)
+	return fm
+}
+
+// splitKeyValue splits "key: value", unquoting the value.
+func splitKeyValue(line string) (key, value string, ok bool) {
+	kv := strings.SplitN(line, ":", 2)
+	if len(kv) != 2 {
+		return "", "", false
 	}
+	return strings.TrimSpace(kv[0]), unquote(strings.TrimSpace(kv[1])), true
 }
 
-// parseHistoryEntryField parses a single field within a history entry.
-func parseHistoryEntryField(line string, entry *HistoryEntry) {
-	if strings.HasPrefix(line, keyHistoryAt) {
-		timeStr := strings.TrimSpace(strings.TrimPrefix(line, keyHistoryAt))
-		if t, err := time.Parse(time.RFC3339, timeStr); err == nil {
-			entry.At = t
-		}
-		return
+// quote wraps s in double quotes, escaping backslashes, quotes and newlines.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// unquote reverses quote. Unquoted values are returned unchanged.
+func unquote(s string) string {
+	// Remove only the outer quotes (not all quotes like Trim does)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
 	}
-	if strings.HasPrefix(line, keyHistoryNote) {
-		note := strings.TrimSpace(strings.TrimPrefix(line, keyHistoryNote))
-		// Remove only the outer quotes (not all quotes like Trim does)
-		if len(note) >= 2 && note[0] == '"' && note[len(note)-1] == '"' {
-			note = note[1 : len(note)-1]
+	s = s[1 : len(s)-1]
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			default:
+				sb.WriteByte(s[i])
+			}
+			continue
 		}
-		// Unescape: order matters - unescape backslashes first, then quotes
-		note = strings.ReplaceAll(note, `\\`, "\x00") // temp placeholder
-		note = strings.ReplaceAll(note, `\"`, `"`)
-		note = strings.ReplaceAll(note, "\x00", `\`)
-		entry.Note = note
-		return
-	}
-	if strings.HasPrefix(line, keyState+":") {
-		entry.State = strings.TrimSpace(strings.TrimPrefix(line, keyState+":"))
+		sb.WriteByte(s[i])
 	}
+	return sb.String()
 }
 
-func parseBody(body string) (intent string, notes []string) {
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// parseBody reads the intent, keeping its paragraphs and lists, and the
+// notes.
+func parseBody(body string) (intent string, notes []Note) {
 	lines := strings.Split(body, "\n")
 	inIntent := false
 	inNotes := false
@@ -257,26 +357,27 @@ func parseBody(body string) (intent string, notes []string) {
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
-		if trimmed == "# Intent" {
+		if trimmed == intentHeading {
 			inIntent = true
 			inNotes = false
 			continue
 		}
-		if trimmed == "## Notes" {
+		if trimmed == notesHeading {
 			inIntent = false
 			inNotes = true
 			continue
 		}
 
-		if inIntent && trimmed != "" {
-			intentLines = append(intentLines, trimmed)
+		if inIntent {
+			intentLines = append(intentLines, line)
 		}
 		if inNotes && strings.HasPrefix(trimmed, "- ") {
-			notes = append(notes, strings.TrimPrefix(trimmed, "- "))
+			notes = append(notes, parseNote(strings.TrimPrefix(trimmed, "- ")))
 		}
 	}
 
-	intent = strings.Join(intentLines, " ")
+	// An intent that can't be cleaned (only blank lines) is empty
+	intent, _ = CleanIntent(strings.Join(intentLines, "\n"))
 	return intent, notes
 }
 
@@ -340,7 +441,7 @@ func (w *Workflow) formatNotes() string {
 	}
 	var noteLines []string
 	for _, n := range w.Notes {
-		noteLines = append(noteLines, "- "+n)
+		noteLines = append(noteLines, formatNote(n))
 	}
 	return strings.Join(noteLines, "\n")
 }
@@ -352,28 +453,73 @@ func (w *Workflow) formatHistory() string {
 	}
 	var lines []string
 	for _, h := range w.History {
-		entry := fmt.Sprintf("  - %s: %s\n    %s %s", keyState, h.State, keyHistoryAt, h.At.Format(time.RFC3339))
+		entry := fmt.Sprintf("  - %s: %s\n    %s: %s", keyState, h.State, keyAt, h.At.Format(time.RFC3339))
+		if h.Actor != "" {
+			entry += fmt.Sprintf("\n    %s: %s", keyActor, quote(h.Actor))
+		}
 		if h.Note != "" {
-			// Escape for YAML: backslashes first, then quotes
-			escapedNote := strings.ReplaceAll(h.Note, `\`, `\\`)
-			escapedNote = strings.ReplaceAll(escapedNote, `"`, `\"`)
-			entry += fmt.Sprintf("\n    %s \"%s\"", keyHistoryNote, escapedNote)
+			entry += fmt.Sprintf("\n    %s: %s", keyNote, quote(h.Note))
 		}
 		lines = append(lines, entry)
 	}
-	return keyHistory + "\n" + strings.Join(lines, "\n")
+	return keyHistory + ":\n" + strings.Join(lines, "\n")
 }
 
-// formatWorkflowContent formats the workflow without the checksum field.
-func (w *Workflow) formatWorkflowContent() string {
-	historySection := w.formatHistory()
-	if historySection != "" {
-		historySection = "\n" + historySection
+// formatUsage returns recorded AI calls formatted for the workflow file.
+func (w *Workflow) formatUsage() string {
+	if len(w.Usage) == 0 {
+		return ""
+	}
+	var lines []string
+	for _, c := range w.Usage {
+		lines = append(lines, fmt.Sprintf("  - %s: %s\n    %s: %s\n    %s: %d\n    %s: %d\n    %s: %s",
+			keyModel, c.Model,
+			keyPurpose, c.Purpose,
+			keyPromptTokens, c.PromptTokens,
+			keyCompletionTokens, c.CompletionTokens,
+			keyAt, c.At.Format(time.RFC3339)))
+	}
+	return keyUsage + ":\n" + strings.Join(lines, "\n")
+}
+
+// formatFrontMatter returns the YAML front matter lines. The checksum line
+// is omitted when checksum is empty.
+func (w *Workflow) formatFrontMatter(checksum string) string {
+	lines := []string{
+		fmt.Sprintf("%s: %s", keyState, w.State),
+		fmt.Sprintf("%s: %d", keySchemaVersion, w.SchemaVersion),
+	}
+	if checksum != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", keyChecksum, checksum))
+	}
+	lines = append(lines, fmt.Sprintf("%s: %s", keyStartedAt, w.StartedAt.Format(time.RFC3339)))
+	if w.Owner != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", keyOwner, quote(w.Owner)))
+	}
+	if w.TwoPerson {
+		lines = append(lines, fmt.Sprintf("%s: true", keyTwoPerson))
+	}
+	if w.FrozenIntent != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", keyFrozenIntent, w.FrozenIntent))
+	}
+	if w.Budget > 0 {
+		lines = append(lines, fmt.Sprintf("%s: %s", keyBudget, strconv.FormatFloat(w.Budget, 'f', -1, 64)))
+	}
+	if w.Appetite > 0 {
+		lines = append(lines, fmt.Sprintf("%s: %s", keyAppetite, FormatDuration(w.Appetite)))
+	}
+	for _, section := range []string{w.formatHistory(), w.formatRevisions(), w.formatConcerns(), w.formatUsage()} {
+		if section != "" {
+			lines = append(lines, section)
+		}
 	}
+	return strings.Join(lines, "\n")
+}
+
+// formatWorkflow renders the complete file around the given front matter.
+func (w *Workflow) formatWorkflow(frontMatter string) string {
 	return fmt.Sprintf(`---
-%s: %s
-%s: %d
-%s: %s%s
+%s
 ---
 
 # Intent
@@ -381,7 +527,12 @@ func (w *Workflow) formatWorkflowContent() string {
 
 ## Notes
 %s
-`, keyState, w.State, keySchemaVersion, w.SchemaVersion, keyStartedAt, w.StartedAt.Format(time.RFC3339), historySection, w.Intent, w.formatNotes())
+`, frontMatter, w.Intent, w.formatNotes())
+}
+
+// formatWorkflowContent formats the workflow without the checksum field.
+func (w *Workflow) formatWorkflowContent() string {
+	return w.formatWorkflow(w.formatFrontMatter(""))
 }
 
 // contentForChecksum returns the content used for checksum computation.
@@ -396,24 +547,7 @@ func (w *Workflow) Format() string {
 	checksum := ComputeChecksum([]byte(checksumContent))
 	w.Checksum = checksum
 
-	historySection := w.formatHistory()
-	if historySection != "" {
-		historySection = "\n" + historySection
-	}
-
-	return fmt.Sprintf(`---
-%s: %s
-%s: %d
-%s: %s
-%s: %s%s
----
-
-# Intent
-%s
-
-## Notes
-%s
-`, keyState, w.State, keySchemaVersion, w.SchemaVersion, keyChecksum, checksum, keyStartedAt, w.StartedAt.Format(time.RFC3339), historySection, w.Intent, w.formatNotes())
+	return w.formatWorkflow(w.formatFrontMatter(checksum))
 }
 
 // ComputeChecksum generates a SHA-256 checksum (first 8 hex chars).
@@ -439,36 +573,31 @@ func Delete() error {
 	}
 
 	// Try to remove .craft dir if empty
-	os.Remove(CraftDir)
+	os.Remove(Dir())
 
 	return nil
 }
 
-// New creates a new workflow with the given intent.
+// New creates a new workflow with the given intent, owned by the current
+// user.
 func New(intent string) *Workflow {
 	now := time.Now().UTC()
+	owner := identity.Current()
 	return &Workflow{
 		State:         state.Thinking,
 		SchemaVersion: SchemaVersion,
 		StartedAt:     now,
+		Owner:         owner,
 		History: []HistoryEntry{{
 			State: string(state.Thinking),
 			At:    now,
+			Actor: owner,
 		}},
 		Intent: intent,
 		Notes:  nil,
 	}
 }
 
-// AddNote appends a note to the workflow.
-func (w *Workflow) AddNote(note string) {
-	note = strings.TrimSpace(note)
-	note = strings.Trim(note, "\"'")
-	if note != "" {
-		w.Notes = append(w.Notes, note)
-	}
-}
-
 // Transition validates and performs a state transition.
 func (w *Workflow) Transition(to state.State) error {
 	return w.TransitionWithNote(to, "")
@@ -484,11 +613,26 @@ func (w *Workflow) TransitionWithNote(to state.State, note string) error {
 	return nil
 }
 
-// RecordTransition adds a history entry for the current state.
+// RecordTransition adds a history entry for the current state, attributed
+// to the current user.
 func (w *Workflow) RecordTransition(note string) {
 	w.History = append(w.History, HistoryEntry{
 		State: string(w.State),
 		At:    time.Now().UTC(),
+		Actor: identity.Current(),
 		Note:  note,
 	})
 }
+
+// RecordUsage appends AI calls to the workflow's usage ledger.
+func (w *Workflow) RecordUsage(calls ...usage.Call) {
+	w.Usage = append(w.Usage, calls...)
+}
+
+// BudgetExceeded returns true if a budget is set and estimated spend has reached it.
+func (w *Workflow) BudgetExceeded(prices usage.PriceTable) bool {
+	if w.Budget <= 0 {
+		return false
+	}
+	return usage.Summarize(w.Usage, prices).Cost >= w.Budget
+}
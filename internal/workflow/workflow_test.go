@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"craft/internal/state"
+	"craft/internal/usage"
 )
 
 func TestNew(t *testing.T) {
@@ -80,7 +81,7 @@ func TestWorkflowRoundTrip(t *testing.T) {
 		t.Errorf("Notes len = %v, want %v", len(parsed.Notes), len(w.Notes))
 	}
 	for i, note := range parsed.Notes {
-		if note != w.Notes[i] {
+		if note.Text != w.Notes[i].Text {
 			t.Errorf("Notes[%d] = %v, want %v", i, note, w.Notes[i])
 		}
 	}
@@ -153,17 +154,17 @@ func TestAddNote(t *testing.T) {
 	w := New("Test")
 
 	w.AddNote("Note 1")
-	if len(w.Notes) != 1 || w.Notes[0] != "Note 1" {
+	if len(w.Notes) != 1 || w.Notes[0].Text != "Note 1" {
 		t.Errorf("AddNote() = %v, want [Note 1]", w.Notes)
 	}
 
 	w.AddNote("  Note 2  ")
-	if len(w.Notes) != 2 || w.Notes[1] != "Note 2" {
+	if len(w.Notes) != 2 || w.Notes[1].Text != "Note 2" {
 		t.Errorf("AddNote() with spaces = %v, want [Note 1, Note 2]", w.Notes)
 	}
 
 	w.AddNote(`"Quoted note"`)
-	if len(w.Notes) != 3 || w.Notes[2] != "Quoted note" {
+	if len(w.Notes) != 3 || w.Notes[2].Text != "Quoted note" {
 		t.Errorf("AddNote() with quotes = %v", w.Notes)
 	}
 
@@ -188,7 +189,7 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 
 	// Check file exists
-	if _, err := os.Stat(filepath.Join(CraftDir, WorkflowFile)); err != nil {
+	if _, err := os.Stat(filepath.Join(DirName, WorkflowFile)); err != nil {
 		t.Errorf("Workflow file not created: %v", err)
 	}
 
@@ -473,3 +474,530 @@ func TestQuoteEscapingEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageRoundTrip(t *testing.T) {
+	w := New("Usage test")
+	w.Budget = 2.5
+	at, _ := time.Parse(time.RFC3339, "2024-01-15T10:30:00Z")
+	w.RecordUsage(usage.Call{Model: "gpt-4o-mini", Purpose: "review", PromptTokens: 812, CompletionTokens: 345, At: at})
+
+	parsed, err := Parse([]byte(w.Format()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if parsed.Budget != 2.5 {
+		t.Errorf("Budget = %v, want 2.5", parsed.Budget)
+	}
+	if len(parsed.Usage) != 1 {
+		t.Fatalf("Usage len = %d, want 1", len(parsed.Usage))
+	}
+	if parsed.Usage[0] != w.Usage[0] {
+		t.Errorf("Usage[0] = %+v, want %+v", parsed.Usage[0], w.Usage[0])
+	}
+	if len(parsed.History) != 1 {
+		t.Errorf("History len = %d, want 1", len(parsed.History))
+	}
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	prices := usage.PriceTable{"m": {Prompt: 1, Completion: 1}}
+	w := New("Budget test")
+
+	w.RecordUsage(usage.Call{Model: "m", PromptTokens: 1000000})
+	if w.BudgetExceeded(prices) {
+		t.Error("BudgetExceeded() = true without a budget")
+	}
+
+	w.Budget = 2
+	if w.BudgetExceeded(prices) {
+		t.Error("BudgetExceeded() = true, spent $1 of $2")
+	}
+
+	w.RecordUsage(usage.Call{Model: "m", CompletionTokens: 1000000})
+	if !w.BudgetExceeded(prices) {
+		t.Error("BudgetExceeded() = false, spent $2 of $2")
+	}
+}
+
+func TestParseAppetite(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"2w", 14 * 24 * time.Hour},
+		{"3d", 72 * time.Hour},
+		{"1w2d", 9 * 24 * time.Hour},
+		{"6h", 6 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseAppetite(tt.in)
+		if err != nil {
+			t.Errorf("ParseAppetite(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAppetite(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		if back, _ := ParseAppetite(FormatDuration(got)); back != got {
+			t.Errorf("FormatDuration(%s) = %q does not round-trip", got, FormatDuration(got))
+		}
+	}
+
+	for _, bad := range []string{"", "2", "w", "2y", "0d", "2w3"} {
+		if _, err := ParseAppetite(bad); err == nil {
+			t.Errorf("ParseAppetite(%q) should fail", bad)
+		}
+	}
+}
+
+func TestAppetiteRoundTrip(t *testing.T) {
+	w := New("Appetite test")
+	w.Appetite = 9 * 24 * time.Hour
+
+	content := w.Format()
+	if !strings.Contains(content, "appetite: 1w2d") {
+		t.Errorf("Format() missing appetite:\n%s", content)
+	}
+
+	parsed, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.Appetite != w.Appetite {
+		t.Errorf("Appetite = %s, want %s", parsed.Appetite, w.Appetite)
+	}
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+}
+
+func TestPhases(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	w := &Workflow{
+		History: []HistoryEntry{
+			{State: "thinking", At: start},
+			{State: "thinking", At: start.Add(1 * time.Hour), Note: "Rejected: too big"},
+			{State: "shaping", At: start.Add(3 * time.Hour)},
+			{State: "building", At: start.Add(4 * time.Hour)},
+			{State: "shipped", At: start.Add(52 * time.Hour)},
+		},
+	}
+
+	phases := w.Phases(start.Add(100 * time.Hour))
+	want := []Phase{
+		{State: state.Thinking, Duration: 3 * time.Hour},
+		{State: state.Shaping, Duration: 1 * time.Hour},
+		{State: state.Building, Duration: 48 * time.Hour},
+	}
+	if len(phases) != len(want) {
+		t.Fatalf("Phases() = %v, want %v", phases, want)
+	}
+	for i := range want {
+		if phases[i] != want[i] {
+			t.Errorf("Phases()[%d] = %v, want %v", i, phases[i], want[i])
+		}
+	}
+
+	w.Appetite = 24 * time.Hour
+	if !w.AppetiteExceeded(start.Add(100 * time.Hour)) {
+		t.Error("AppetiteExceeded() = false, built 2d against 1d")
+	}
+	w.Appetite = 72 * time.Hour
+	if w.AppetiteExceeded(start.Add(100 * time.Hour)) {
+		t.Error("AppetiteExceeded() = true, built 2d against 3d")
+	}
+}
+
+func TestConcernsRoundTrip(t *testing.T) {
+	w := New("Concern test")
+	w.RaiseConcern(ConcernReject, `Who "owns" this?`)
+	c := w.RaiseConcern(ConcernRevise, "Cards overlap")
+	if c.ID != "C2" {
+		t.Errorf("ID = %s, want C2", c.ID)
+	}
+	if err := w.ResolveConcern("C1", "Platform team", false); err != nil {
+		t.Fatalf("ResolveConcern() error = %v", err)
+	}
+
+	parsed, err := Parse([]byte(w.Format()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+	if len(parsed.Concerns) != 2 {
+		t.Fatalf("Concerns = %+v, want 2", parsed.Concerns)
+	}
+	first := parsed.Concerns[0]
+	if first.Text != `Who "owns" this?` || first.Status != ConcernResolved || first.Answer != "Platform team" || first.ResolvedAt.IsZero() {
+		t.Errorf("Concerns[0] = %+v", first)
+	}
+	if open := parsed.OpenConcerns(ConcernRevise); len(open) != 1 || open[0].ID != "C2" {
+		t.Errorf("OpenConcerns(revise) = %+v", open)
+	}
+	if open := parsed.OpenConcerns(ConcernReject); len(open) != 0 {
+		t.Errorf("OpenConcerns(reject) = %+v, want none", open)
+	}
+}
+
+func TestTypedNotesRoundTrip(t *testing.T) {
+	w := New("Note test")
+	w.State = state.Building
+	w.AddTypedNote(NoteDecision, "Use token bucket --> not leaky bucket")
+	w.AddNote("Plain note")
+	w.Notes[0].Author = `Jo "JJ" Lee`
+
+	content := w.Format()
+	if !strings.Contains(content, "- Use token bucket --> not leaky bucket <!-- type=decision author=") {
+		t.Errorf("Format() note line missing metadata:\n%s", content)
+	}
+
+	parsed, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+	if len(parsed.Notes) != 2 {
+		t.Fatalf("Notes = %+v, want 2", parsed.Notes)
+	}
+
+	got := parsed.Notes[0]
+	want := w.Notes[0]
+	if got.Text != want.Text || got.Type != NoteDecision || got.Author != want.Author || got.Phase != state.Building || !got.At.Equal(want.At.Truncate(time.Second)) {
+		t.Errorf("Notes[0] = %+v, want %+v", got, want)
+	}
+	if parsed.Notes[1].Type != NoteGeneral {
+		t.Errorf("Notes[1].Type = %q, want %q", parsed.Notes[1].Type, NoteGeneral)
+	}
+
+	if texts := parsed.NoteTexts(); texts[0] != "[decision] Use token bucket --> not leaky bucket" || texts[1] != "Plain note" {
+		t.Errorf("NoteTexts() = %v", texts)
+	}
+	if decisions := parsed.NotesByType(NoteDecision); len(decisions) != 1 {
+		t.Errorf("NotesByType(decision) = %v, want 1 note", decisions)
+	}
+}
+
+func TestNoteTextWithComment(t *testing.T) {
+	texts := []string{
+		"Keep the marker <!-- type=risk -->",
+		`Already escaped \<!-- x -->`,
+		"Unclosed <!-- comment",
+	}
+	for _, text := range texts {
+		w := New("Comment test")
+		w.AddTypedNote(NoteDecision, text)
+		w.Notes = append(w.Notes, Note{Text: text, Type: NoteGeneral}) // Without metadata
+
+		parsed, err := Parse([]byte(w.Format()))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if err := parsed.ValidateChecksum(); err != nil {
+			t.Errorf("ValidateChecksum() error = %v", err)
+		}
+		for i, want := range []string{NoteDecision, NoteGeneral} {
+			if got := parsed.Notes[i]; got.Text != text || got.Type != want {
+				t.Errorf("Notes[%d] = %q (%s), want %q (%s)", i, got.Text, got.Type, text, want)
+			}
+		}
+	}
+}
+
+func TestLegacyNotesKeepChecksum(t *testing.T) {
+	w := New("Legacy")
+	w.Notes = []Note{{Text: "Written before notes had metadata", Type: NoteGeneral}}
+	content := w.Format()
+	if strings.Contains(content, "<!--") {
+		t.Errorf("note without metadata should be plain:\n%s", content)
+	}
+
+	parsed, _ := Parse([]byte(content))
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+}
+
+func TestDirWalksUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	New("Walk up").Save()
+	os.MkdirAll(filepath.Join("internal", "foo"), 0755)
+	os.Chdir(filepath.Join("internal", "foo"))
+
+	if want := filepath.Join("..", "..", DirName); Dir() != want {
+		t.Errorf("Dir() = %q, want %q", Dir(), want)
+	}
+	if !Exists() {
+		t.Error("Exists() should find the workflow from a subdirectory")
+	}
+}
+
+func TestDirStopsAtGitRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+
+	// A workflow above the repository is not used by it
+	os.MkdirAll(filepath.Join(tmpDir, DirName), 0755)
+	repo := filepath.Join(tmpDir, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	os.MkdirAll(filepath.Join(repo, "sub"), 0755)
+	os.Chdir(filepath.Join(repo, "sub"))
+
+	if want := filepath.Join("..", DirName); Dir() != want {
+		t.Errorf("Dir() = %q, want %q (the git root)", Dir(), want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+
+	os.MkdirAll(".git", 0755)
+	os.MkdirAll(filepath.Join("services", "api"), 0755)
+
+	// The repository root is always listed and never registered
+	if err := Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	t.Setenv(EnvDir, filepath.Join("services", "api"))
+	for range 2 {
+		if err := Register(); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	dirs, err := Registered()
+	if err != nil {
+		t.Fatalf("Registered() error = %v", err)
+	}
+	if strings.Join(dirs, ",") != ".,services/api" {
+		t.Errorf("Registered() = %v, want [. services/api]", dirs)
+	}
+}
+
+func TestWorkflows(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(EnvDir, "")
+
+	os.MkdirAll(".git", 0755)
+	for _, dir := range []string{"services/api", "services/web", "tools/gen", "node_modules/dep"} {
+		os.MkdirAll(filepath.Join(dir, DirName), 0755)
+		os.WriteFile(filepath.Join(dir, DirName, WorkflowFile), []byte("x"), 0644)
+	}
+	os.MkdirAll(filepath.Join("services", "empty", DirName), 0755)
+
+	t.Setenv(EnvDir, filepath.Join("services", "web"))
+	Register()
+	t.Setenv(EnvDir, "")
+
+	dirs, err := Workflows()
+	if err != nil {
+		t.Fatalf("Workflows() error = %v", err)
+	}
+	// Registered first, then the unregistered ones found on disk
+	if got := strings.Join(dirs, ","); got != ".,services/web,services/api,tools/gen" {
+		t.Errorf("Workflows() = %s, want .,services/web,services/api,tools/gen", got)
+	}
+}
+
+// setIdentity makes identity.Current return name, whatever git is
+// configured with on this machine.
+func setIdentity(t *testing.T, name string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "user.name")
+	t.Setenv("GIT_CONFIG_VALUE_0", name)
+}
+
+func TestOwnerAndActorsRoundTrip(t *testing.T) {
+	setIdentity(t, "Alice")
+	w := New("Ownership test")
+	if w.Owner != "Alice" || w.History[0].Actor != "Alice" {
+		t.Errorf("New() owner = %q, actor = %q, want Alice", w.Owner, w.History[0].Actor)
+	}
+
+	setIdentity(t, "Bob")
+	w.AddNote("Looked at the limiter")
+	if err := w.Handoff("Carol <carol@example.com>", "Pitch drafted, cards next"); err != nil {
+		t.Fatalf("Handoff() error = %v", err)
+	}
+
+	parsed, err := Parse([]byte(w.Format()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+	if parsed.Owner != "Carol <carol@example.com>" {
+		t.Errorf("Owner = %q, want Carol", parsed.Owner)
+	}
+	last := parsed.History[len(parsed.History)-1]
+	if last.Actor != "Bob" || last.Note != "Handed off from Alice to Carol <carol@example.com>: Pitch drafted, cards next" {
+		t.Errorf("last history = %+v", last)
+	}
+	if got := parsed.Contributors(); len(got) != 2 || got[0] != "Alice" || got[1] != "Bob" {
+		t.Errorf("Contributors() = %v, want [Alice Bob]", got)
+	}
+}
+
+func TestHandoffErrors(t *testing.T) {
+	setIdentity(t, "Alice")
+	w := New("Handoff test")
+
+	if err := w.Handoff("", "context"); err == nil {
+		t.Error("Handoff() without a person should fail")
+	}
+	if err := w.Handoff("Bob", " "); err == nil {
+		t.Error("Handoff() without context should fail")
+	}
+	if err := w.Handoff("Alice", "context"); err == nil {
+		t.Error("Handoff() to the owner should fail")
+	}
+	if len(w.History) != 1 {
+		t.Errorf("failed handoffs recorded history: %+v", w.History)
+	}
+}
+
+func TestCheckTwoPerson(t *testing.T) {
+	setIdentity(t, "Alice <alice@example.com>")
+	w := New("Two-person test")
+	w.RecordTransition("reject") // Still thinking
+	if err := w.TransitionWithNote(state.Shaping, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := w.AcceptedBy(); got != "Alice <alice@example.com>" {
+		t.Errorf("AcceptedBy() = %q, want Alice", got)
+	}
+	if _, err := w.CheckTwoPerson("Alice"); err == nil {
+		t.Error("CheckTwoPerson() by the accepter should fail")
+	}
+	if _, err := w.CheckTwoPerson(""); err == nil {
+		t.Error("CheckTwoPerson() without an identity should fail")
+	}
+	if got, err := w.CheckTwoPerson("Bob"); err != nil || got != "Alice <alice@example.com>" {
+		t.Errorf("CheckTwoPerson(Bob) = %q, %v, want Alice", got, err)
+	}
+
+	parsed, _ := Parse([]byte(w.Format()))
+	if parsed.TwoPerson {
+		t.Error("TwoPerson = true, want false by default")
+	}
+	w.TwoPerson = true
+	if parsed, _ := Parse([]byte(w.Format())); !parsed.TwoPerson {
+		t.Error("TwoPerson not kept through Format and Parse")
+	}
+}
+
+func TestMultiLineIntentRoundTrip(t *testing.T) {
+	intent := "Add rate limiting to the public API\n\nClients hammer /search during sales.\n\n## Success criteria\n- 429 above 100 req/s\n  - with Retry-After\n- No change for existing keys"
+	w := New(intent)
+	w.AddNote("Check the CDN first")
+
+	parsed, err := Parse([]byte(w.Format()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.Intent != intent {
+		t.Errorf("Intent = %q, want %q", parsed.Intent, intent)
+	}
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+	if len(parsed.Notes) != 1 {
+		t.Errorf("Notes = %+v, want 1", parsed.Notes)
+	}
+	if got := parsed.Title(); got != "Add rate limiting to the public API" {
+		t.Errorf("Title() = %q", got)
+	}
+}
+
+func TestCleanIntent(t *testing.T) {
+	tests := []struct {
+		in, want string
+		err      bool
+	}{
+		{in: "  One line  ", want: "  One line"},
+		{in: "\r\n\nTitle\r\n\r\n\r\n\r\nBody  \n\n", want: "Title\n\nBody"},
+		{in: "- a\n  - b\n- c", want: "- a\n  - b\n- c"},
+		{in: "\n \n", err: true},
+		{in: "Title\n## Notes\nx", err: true},
+		{in: "# Intent\nTitle", err: true},
+	}
+	for _, tt := range tests {
+		got, err := CleanIntent(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("CleanIntent(%q) = %q, %v, want %q (error %v)", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestReviseIntent(t *testing.T) {
+	setIdentity(t, "Alice")
+	w := New("Add rate limiting")
+
+	if err := w.ReviseIntent("Add rate limiting to the public API", ""); err == nil {
+		t.Error("ReviseIntent() without a reason should fail")
+	}
+	if err := w.ReviseIntent("Add rate limiting", "no change"); err == nil {
+		t.Error("ReviseIntent() with the same intent should fail")
+	}
+	if err := w.ReviseIntent("Add rate limiting to the public API\n\n- 429 above 100 req/s", `Scope to "public" API`); err != nil {
+		t.Fatalf("ReviseIntent() error = %v", err)
+	}
+
+	parsed, err := Parse([]byte(w.Format()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := parsed.ValidateChecksum(); err != nil {
+		t.Errorf("ValidateChecksum() error = %v", err)
+	}
+	if len(parsed.Revisions) != 1 {
+		t.Fatalf("Revisions = %+v, want 1", parsed.Revisions)
+	}
+	r := parsed.Revisions[0]
+	if r.Intent != "Add rate limiting" || r.Reason != `Scope to "public" API` || r.Actor != "Alice" || r.At.IsZero() {
+		t.Errorf("Revisions[0] = %+v", r)
+	}
+	if parsed.Intent != "Add rate limiting to the public API\n\n- 429 above 100 req/s" {
+		t.Errorf("Intent = %q", parsed.Intent)
+	}
+
+	parsed.FrozenIntent = parsed.HashIntent()
+	if err := parsed.TransitionWithNote(state.Shaping, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.ReviseIntent("Something else", "too late"); err == nil {
+		t.Error("ReviseIntent() after accept should fail")
+	}
+
+	frozen, _ := Parse([]byte(parsed.Format()))
+	if frozen.FrozenIntent == "" || frozen.IntentChangedSinceAccept() {
+		t.Errorf("FrozenIntent = %q, changed = %v", frozen.FrozenIntent, frozen.IntentChangedSinceAccept())
+	}
+	frozen.Intent = "Edited by hand"
+	if !frozen.IntentChangedSinceAccept() {
+		t.Error("IntentChangedSinceAccept() = false after the intent changed")
+	}
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"craft/cmd"
+	"craft/internal/workflow"
 )
 
 const version = "0.5.0"
@@ -14,76 +16,102 @@ func main() {
 }
 
 func run(args []string) int {
+	args, ok := globalFlags(args)
+	if !ok {
+		return 1
+	}
+
 	if len(args) == 0 {
 		printHelp()
 		return 0
 	}
 
 	switch args[0] {
-	case "--help", "-h", "help":
+	case "--help", "-h":
 		printHelp()
 		return 0
+	case "help":
+		return help(args[1:])
 	case "--version", "-v":
 		fmt.Println("craft version " + version)
 		return 0
-	case "start":
-		return cmd.Start(args[1:])
-	case "think":
-		return cmd.Think(args[1:])
-	case "accept":
-		return cmd.Accept(args[1:])
-	case "reject":
-		return cmd.Reject(args[1:])
-	case "shape":
-		return cmd.Shape(args[1:])
-	case "approve":
-		return cmd.Approve(args[1:])
-	case "revise":
-		return cmd.Revise(args[1:])
-	case "ship":
-		return cmd.Ship(args[1:])
-	case "status":
-		return cmd.Status(args[1:])
-	case "reset":
-		return cmd.Reset(args[1:])
-	case "init":
-		return cmd.Init(args[1:])
-	default:
+	}
+
+	c := cmd.Lookup(args[0])
+	if c == nil {
 		fmt.Fprintf(os.Stderr, "Error: Unknown command '%s'\n", args[0])
 		fmt.Fprintln(os.Stderr, "Run 'craft --help' for usage.")
 		return 1
 	}
+	return c.Execute(args[1:])
+}
+
+// globalFlags applies the flags given before the command and returns the
+// remaining arguments. --dir selects the project directory, as CRAFT_DIR does.
+func globalFlags(args []string) ([]string, bool) {
+	for len(args) > 0 && (args[0] == "--dir" || strings.HasPrefix(args[0], "--dir=")) {
+		dir, hasValue := strings.CutPrefix(args[0], "--dir=")
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: flag --dir requires a value")
+				return nil, false
+			}
+			dir, args = args[0], args[1:]
+		}
+
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: --dir %s is not a directory\n", dir)
+			return nil, false
+		}
+		os.Setenv(workflow.EnvDir, dir)
+	}
+	return args, true
+}
+
+// help prints general help, or a command's own help for `craft help <command>`.
+func help(args []string) int {
+	if len(args) == 0 {
+		printHelp()
+		return 0
+	}
+	c := cmd.Lookup(args[0])
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "Error: Unknown command '%s'\n", args[0])
+		return 1
+	}
+	c.WriteHelp(os.Stdout)
+	return 0
 }
 
 func printHelp() {
 	fmt.Print(`craft - deliberate judgment before execution
 
 Usage:
-  craft <command> [arguments]
+  craft [--dir=<path>] <command> [arguments]
+  craft <command> --help
 
 Commands:
-  start "<intent>"   Begin a new workflow with the given intent
-  think              Review the current workflow state
-  accept [note]      Confirm alignment and advance to shaping
-  reject [note]      Record a concern, stay in thinking
-  shape              Show shaping status
-  shape --generate   Generate pitch and cards using AI
-  approve            Approve structure and advance to building
-  revise "note"      Record a concern during shaping
-  ship               Finalize the workflow
-  status             Show current state and valid actions
-  reset              Abandon current workflow
-  init [flags]       Copy AI integration templates
-
-Accept flags:
-  --skip-shaping     Skip shaping phase, advance directly to building
-
-Init flags:
-  --claude           Copy Claude Code templates (CLAUDE.md, .claude/commands/)
-  --cursor           Copy Cursor rules (.cursorrules)
-  --all              Copy all templates
+`)
+	for _, c := range cmd.Commands {
+		if !c.Hidden {
+			c.WriteSummary(os.Stdout)
+		}
+	}
+
+	for _, c := range cmd.Commands {
+		if c.Hidden || len(c.Flags) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s flags:\n", strings.ToUpper(c.Name[:1])+c.Name[1:])
+		c.WriteFlags(os.Stdout)
+	}
+
+	fmt.Print(`
+Global flags:
+  --dir=<path>       Use the workflow in <path>/.craft (or set CRAFT_DIR)
 
 Workflow states: thinking → shaping → building → shipped
-State is stored in .craft/workflow.md
+State is stored in .craft/workflow.md, found by searching up to the git root
 `)
 }
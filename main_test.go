@@ -2,7 +2,10 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+
+	"craft/internal/workflow"
 )
 
 func setupTest(t *testing.T) (cleanup func()) {
@@ -10,6 +13,10 @@ func setupTest(t *testing.T) (cleanup func()) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
 	os.Chdir(tmpDir)
+	// Keep a developer's own ~/.config/craft out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
 	return func() {
 		os.Chdir(origDir)
 	}
@@ -23,6 +30,9 @@ func TestRunHelp(t *testing.T) {
 		{[]string{"--help"}},
 		{[]string{"-h"}},
 		{[]string{"help"}},
+		{[]string{"help", "accept"}},
+		{[]string{"accept", "--help"}},
+		{[]string{"reset", "-h"}},
 	}
 
 	for _, tt := range tests {
@@ -84,6 +94,12 @@ func TestRunCommands(t *testing.T) {
 		t.Errorf("reject = %d, want 0", code)
 	}
 
+	// Test resolve (accept is blocked until the concern is answered)
+	code = run([]string{"resolve", "C1", "Thought it through"})
+	if code != 0 {
+		t.Errorf("resolve = %d, want 0", code)
+	}
+
 	// Test accept (with --skip-shaping to go directly to building)
 	code = run([]string{"accept", "--skip-shaping"})
 	if code != 0 {
@@ -102,3 +118,21 @@ func TestRunCommands(t *testing.T) {
 		t.Errorf("reset = %d, want 0", code)
 	}
 }
+
+func TestRunDir(t *testing.T) {
+	cleanup := setupTest(t)
+	defer cleanup()
+	t.Setenv(workflow.EnvDir, "")
+
+	os.MkdirAll("services/api", 0755)
+	if code := run([]string{"--dir", "services/api", "start", "API work"}); code != 0 {
+		t.Fatalf("run(--dir services/api start) = %d, want 0", code)
+	}
+	if _, err := os.Stat("services/api/.craft/workflow.md"); err != nil {
+		t.Errorf("--dir workflow not created in package: %v", err)
+	}
+
+	if code := run([]string{"--dir=missing", "status"}); code != 1 {
+		t.Errorf("run(--dir=missing status) = %d, want 1", code)
+	}
+}